@@ -38,7 +38,11 @@ func BwDataPair(column string, fieldName ...string) Bw {
 }
 
 func (f Bw) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildRangePair(f, data, ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Bw) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildRangePair(f, data, "BETWEEN", bs, offset)
 }
 
 func (f Bw) GetPair() any {
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const importPath = "github.com/eaglebush/filterbuilder/v2"
+
+var (
+	ErrNoStructs      = errors.New("no structs with db tags found")
+	ErrPackageMixed   = errors.New("input files are from different packages")
+	ErrNameConflict   = errors.New("generated name conflicts with another")
+	ErrStructNotFound = errors.New("struct not found or has no db tags")
+)
+
+// model is a struct with db tags
+type model struct {
+	Name   string
+	Fields []field
+}
+
+// field is a tagged field of a model
+type field struct {
+	Name    string // Go name of the field
+	Column  string // Column name from the db tag
+	GoType  string // Type of the values compared with the column
+	ColType string // filterbuilder.ColumnType of the column
+	Imports []string
+}
+
+// fieldType is how a Go type of a field maps to a column
+type fieldType struct {
+	goType  string
+	colType string
+	imports []string
+}
+
+// nullTypes are the database/sql null types and the types of their values
+var nullTypes = map[string]fieldType{
+	"NullString":  {goType: "string", colType: "TypeString"},
+	"NullInt64":   {goType: "int64", colType: "TypeInt"},
+	"NullInt32":   {goType: "int32", colType: "TypeInt"},
+	"NullInt16":   {goType: "int16", colType: "TypeInt"},
+	"NullByte":    {goType: "byte", colType: "TypeInt"},
+	"NullBool":    {goType: "bool", colType: "TypeBool"},
+	"NullFloat64": {goType: "float64", colType: "TypeAny"},
+	"NullTime":    {goType: "time.Time", colType: "TypeTime", imports: []string{"time"}},
+}
+
+// source is a struct type of the input files with the imports of its file
+type source struct {
+	spec    *ast.TypeSpec
+	imports map[string]string
+}
+
+// Generate reads the structs with db tags of the files and returns the formatted source of their columns.
+// When names are given, only those structs are generated.
+// The fields of embedded structs are generated with those of the struct when the embedded struct is in the files.
+// Embedded structs that cannot be read are left out with a warning.
+func Generate(files []string, names []string) ([]byte, []string, error) {
+	fset := token.NewFileSet()
+	pkg := ""
+	var order []string
+	structs := map[string]source{}
+	for _, fn := range files {
+		f, err := parser.ParseFile(fset, fn, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pkg != "" && pkg != f.Name.Name {
+			return nil, nil, ErrPackageMixed
+		}
+		pkg = f.Name.Name
+
+		imports := fileImports(f)
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if _, ok := ts.Type.(*ast.StructType); ok && ts.TypeParams == nil {
+					structs[ts.Name.Name] = source{spec: ts, imports: imports}
+					order = append(order, ts.Name.Name)
+				}
+			}
+		}
+	}
+
+	var (
+		models   []model
+		warnings []string
+	)
+	for _, n := range order {
+		if len(names) > 0 && !slices.Contains(names, n) {
+			continue
+		}
+		m := model{Name: n}
+		m.Fields, warnings = readFields(structs[n], structs, []string{n}, warnings)
+		if len(m.Fields) > 0 {
+			models = append(models, m)
+		}
+	}
+
+	for _, n := range names {
+		if !slices.ContainsFunc(models, func(m model) bool { return m.Name == n }) {
+			return nil, warnings, fmt.Errorf("%w: %s", ErrStructNotFound, n)
+		}
+	}
+	if len(models) == 0 {
+		return nil, warnings, ErrNoStructs
+	}
+	src, err := render(pkg, models)
+	return src, warnings, err
+}
+
+// fileImports maps the package names of the imports of a file to their paths
+func fileImports(f *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, is := range f.Imports {
+		p, _ := strconv.Unquote(is.Path.Value)
+		name := path.Base(p)
+		if is.Name != nil {
+			name = is.Name.Name
+		}
+		imports[name] = p
+	}
+	return imports
+}
+
+// readFields reads the tagged fields of a struct, with those of its embedded structs.
+// The fields of an embedded struct keep their own columns, even when the embedded struct has a db tag:
+// the dotted name that sqlx maps such fields by is not a column of the table.
+// The seen structs are those being read, to stop at embedded structs that embed themselves.
+func readFields(src source, structs map[string]source, seen []string, warnings []string) ([]field, []string) {
+	var fields []field
+	for _, fl := range src.spec.Type.(*ast.StructType).Fields.List {
+		col := dbColumn(fl.Tag)
+		if len(fl.Names) == 0 {
+			if isSkipped(fl.Tag) {
+				continue
+			}
+			name := embeddedName(fl.Type)
+			emb, ok := structs[name]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("%s: embedded %s left out: not a struct of the input files", src.spec.Name.Name, exprString(fl.Type)))
+				continue
+			}
+			if slices.Contains(seen, name) {
+				warnings = append(warnings, fmt.Sprintf("%s: embedded %s left out: it embeds itself", src.spec.Name.Name, exprString(fl.Type)))
+				continue
+			}
+			var embFields []field
+			embFields, warnings = readFields(emb, structs, append(slices.Clip(seen), name), warnings)
+			fields = append(fields, embFields...)
+			continue
+		}
+		if col == "" {
+			continue
+		}
+		ft := resolveType(fl.Type, src.imports)
+		for _, id := range fl.Names {
+			if !id.IsExported() {
+				continue
+			}
+			fields = append(fields, field{Name: id.Name, Column: col, GoType: ft.goType, ColType: ft.colType, Imports: ft.imports})
+		}
+	}
+	return fields, warnings
+}
+
+// embeddedName gets the name of an embedded type of the package, or an empty string when it is from another package
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// isSkipped tells if a field is tagged db:"-"
+func isSkipped(tag *ast.BasicLit) bool {
+	if tag == nil {
+		return false
+	}
+	t, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return false
+	}
+	col, _, _ := strings.Cut(reflect.StructTag(t).Get("db"), ",")
+	return col == "-"
+}
+
+// dbColumn gets the column name of a db tag, or an empty string when there is none
+func dbColumn(tag *ast.BasicLit) string {
+	if tag == nil {
+		return ""
+	}
+	t, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return ""
+	}
+	col, _, _ := strings.Cut(reflect.StructTag(t).Get("db"), ",")
+	if col == "-" {
+		return ""
+	}
+	return col
+}
+
+// resolveType maps the type of a field to the type of its column values
+func resolveType(expr ast.Expr, imports map[string]string) fieldType {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return resolveType(t.X, imports)
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return fieldType{goType: t.Name, colType: "TypeString"}
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune":
+			return fieldType{goType: t.Name, colType: "TypeInt"}
+		case "bool":
+			return fieldType{goType: t.Name, colType: "TypeBool"}
+		}
+	case *ast.IndexExpr:
+		// sql.Null[T]
+		if sel, ok := t.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "Null" && importOf(sel, imports) == "database/sql" {
+			return resolveType(t.Index, imports)
+		}
+	case *ast.SelectorExpr:
+		switch p := importOf(t, imports); {
+		case p == "time" && t.Sel.Name == "Time":
+			return fieldType{goType: "time.Time", colType: "TypeTime", imports: []string{"time"}}
+		case p == "database/sql":
+			if nt, ok := nullTypes[t.Sel.Name]; ok {
+				return nt
+			}
+		case p == "github.com/shopspring/decimal" && t.Sel.Name == "Decimal":
+			return fieldType{goType: exprString(t), colType: "TypeDecimal", imports: usedImports(t, imports)}
+		case strings.HasSuffix(p, "uuid") && t.Sel.Name == "UUID":
+			return fieldType{goType: exprString(t), colType: "TypeUUID", imports: usedImports(t, imports)}
+		}
+	}
+	return fieldType{goType: exprString(expr), colType: "TypeAny", imports: usedImports(expr, imports)}
+}
+
+// importOf gets the import path of the package of a selector
+func importOf(sel *ast.SelectorExpr, imports map[string]string) string {
+	if id, ok := sel.X.(*ast.Ident); ok {
+		return imports[id.Name]
+	}
+	return ""
+}
+
+// usedImports gets the imports referenced by a type expression
+func usedImports(expr ast.Expr, imports map[string]string) []string {
+	var used []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				if p, ok := imports[id.Name]; ok {
+					if path.Base(p) != id.Name {
+						p = id.Name + " " + strconv.Quote(p)
+					}
+					used = append(used, p)
+				}
+			}
+		}
+		return true
+	})
+	return used
+}
+
+func exprString(expr ast.Expr) string {
+	var b bytes.Buffer
+	format.Node(&b, token.NewFileSet(), expr)
+	return b.String()
+}
+
+// render writes the columns, schemas and search DTOs of the models
+func render(pkg string, models []model) ([]byte, error) {
+	imports := map[string]bool{}
+	declared := map[string]bool{}
+	declare := func(name string) error {
+		if declared[name] {
+			return fmt.Errorf("%w: %s", ErrNameConflict, name)
+		}
+		declared[name] = true
+		return nil
+	}
+
+	var body bytes.Buffer
+	for _, m := range models {
+		for _, n := range []string{m.Name + "Schema", m.Name + "Search"} {
+			if err := declare(n); err != nil {
+				return nil, err
+			}
+		}
+
+		fmt.Fprintf(&body, "\n// Columns of %s\nvar (\n", m.Name)
+		for _, f := range m.Fields {
+			if err := declare(m.Name + f.Name); err != nil {
+				return nil, err
+			}
+			if f.GoType == "string" {
+				fmt.Fprintf(&body, "\t%s%s = filterbuilder.NewTextCol(%q)\n", m.Name, f.Name, f.Column)
+				continue
+			}
+			fmt.Fprintf(&body, "\t%s%s = filterbuilder.Col[%s](%q)\n", m.Name, f.Name, f.GoType, f.Column)
+		}
+		fmt.Fprint(&body, ")\n")
+
+		fmt.Fprintf(&body, "\n// %sSchema is the registry of the columns of %s that filters can reference\n", m.Name, m.Name)
+		fmt.Fprintf(&body, "var %sSchema = filterbuilder.Schema{\n", m.Name)
+		for _, f := range m.Fields {
+			if f.ColType == "TypeAny" {
+				fmt.Fprintf(&body, "\t%q: {},\n", f.Column)
+				continue
+			}
+			fmt.Fprintf(&body, "\t%q: {Type: filterbuilder.%s},\n", f.Column, f.ColType)
+		}
+		fmt.Fprint(&body, "}\n")
+
+		// The sort field falls back to OrderBy when a field or column already takes its name
+		sortField, sortKey := "Sort", "sort"
+		taken := func(f field) bool { return f.Name == sortField || f.Column == sortKey }
+		if slices.ContainsFunc(m.Fields, taken) {
+			sortField, sortKey = "OrderBy", "order_by"
+		}
+		if slices.ContainsFunc(m.Fields, taken) {
+			return nil, fmt.Errorf("%w: %s.%s", ErrNameConflict, m.Name+"Search", sortField)
+		}
+		if slices.ContainsFunc(m.Fields, func(f field) bool { return f.Name == "Filter" }) {
+			return nil, fmt.Errorf("%w: %s.Filter", ErrNameConflict, m.Name+"Search")
+		}
+
+		fmt.Fprintf(&body, "\n// %sSearch is a search request of %s. Nil fields are not filtered on.\n", m.Name, m.Name)
+		fmt.Fprintf(&body, "type %sSearch struct {\n", m.Name)
+		for _, f := range m.Fields {
+			fmt.Fprintf(&body, "\t%s *%s `json:\"%s,omitempty\"`\n", f.Name, f.GoType, f.Column)
+		}
+		fmt.Fprintf(&body, "\t%s []filterbuilder.Sort `json:\"%s,omitempty\"`\n}\n", sortField, sortKey)
+
+		fmt.Fprintf(&body, "\n// Filter builds a filter of the fields of the search that are set, limited to %sSchema\n", m.Name)
+		fmt.Fprintf(&body, "func (s *%sSearch) Filter(opts ...filterbuilder.FilterOption) *filterbuilder.Filter {\n", m.Name)
+		fmt.Fprintf(&body, "\tfb := filterbuilder.New(append([]filterbuilder.FilterOption{filterbuilder.UseSchema(%sSchema)}, opts...)...)\n", m.Name)
+		for _, f := range m.Fields {
+			fmt.Fprintf(&body, "\tif s.%s != nil {\n\t\tfb.Eq = append(fb.Eq, %s%s.Eq(*s.%s))\n\t}\n", f.Name, m.Name, f.Name, f.Name)
+		}
+		fmt.Fprintf(&body, "\tfb.Sort = s.%s\n\treturn fb\n}\n", sortField)
+
+		for _, f := range m.Fields {
+			for _, is := range f.Imports {
+				imports[is] = true
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by filtergen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	std, other := []string{}, []string{strconv.Quote(importPath)}
+	for is := range imports {
+		if !strings.Contains(is, " ") {
+			is = strconv.Quote(is)
+		}
+		// Standard library paths have no dot in their first element
+		first, _, _ := strings.Cut(is[strings.Index(is, `"`)+1:], "/")
+		if strings.Contains(first, ".") {
+			other = append(other, is)
+			continue
+		}
+		std = append(std, is)
+	}
+	slices.Sort(std)
+	slices.Sort(other)
+	for _, is := range std {
+		fmt.Fprintf(&out, "\t%s\n", is)
+	}
+	if len(std) > 0 {
+		fmt.Fprint(&out, "\n")
+	}
+	for _, is := range other {
+		fmt.Fprintf(&out, "\t%s\n", is)
+	}
+	fmt.Fprint(&out, ")\n")
+	out.Write(body.Bytes())
+	return format.Source(out.Bytes())
+}
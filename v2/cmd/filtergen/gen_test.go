@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const models = `package store
+
+import (
+	"database/sql"
+	"time"
+
+	dec "github.com/shopspring/decimal"
+)
+
+type Audit struct {
+	CreatedBy string    ` + "`db:\"created_by\"`" + `
+	Created   time.Time ` + "`db:\"created_at\"`" + `
+}
+
+type User struct {
+	*Audit
+	ID     int64          ` + "`db:\"id\"`" + `
+	Name   string         ` + "`db:\"name,omitempty\"`" + `
+	Nick   sql.NullString ` + "`db:\"nick\"`" + `
+	Active *bool          ` + "`db:\"active\"`" + `
+	Sort   int            ` + "`db:\"sort\"`" + `
+	secret string         ` + "`db:\"secret\"`" + `
+	Skip   string         ` + "`db:\"-\"`" + `
+	Plain  string
+}
+
+type Order struct {
+	Audit  ` + "`db:\"audit\"`" + `
+	sql.RawBytes
+	Amount dec.Decimal              ` + "`db:\"amount\"`" + `
+	Seen   sql.Null[time.Time]      ` + "`db:\"seen\"`" + `
+	Tags   []string                 ` + "`db:\"tags\"`" + `
+}
+
+type Other struct{ X int }
+`
+
+func TestGenerate(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "models.go")
+	if err := os.WriteFile(fn, []byte(models), 0o644); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+
+	src, warnings, err := Generate([]string{fn}, []string{"User", "Order"})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	out := string(src)
+	for _, want := range []string{
+		"package store",
+		`dec "github.com/shopspring/decimal"`,
+		`UserCreatedBy = filterbuilder.NewTextCol("created_by")`,
+		`UserID        = filterbuilder.Col[int64]("id")`,
+		`UserNick      = filterbuilder.NewTextCol("nick")`,
+		`UserCreated   = filterbuilder.Col[time.Time]("created_at")`,
+		`OrderCreated   = filterbuilder.Col[time.Time]("created_at")`,
+		`"name":       {Type: filterbuilder.TypeString},`,
+		`OrderAmount    = filterbuilder.Col[dec.Decimal]("amount")`,
+		"[]filterbuilder.Sort `json:\"order_by,omitempty\"`",
+		"fb.Eq = append(fb.Eq, UserSort.Eq(*s.Sort))",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in\n%s", want, out)
+		}
+	}
+	for _, skip := range []string{"secret", "Skip", "Plain", "Other", "AuditSchema", "audit."} {
+		if strings.Contains(out, skip) {
+			t.Errorf("unexpected %s in\n%s", skip, out)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "sql.RawBytes") {
+		t.Errorf("got warnings %q, want one for sql.RawBytes", warnings)
+	}
+
+	checkGenerated(t, src)
+
+	if _, _, err := Generate([]string{fn}, []string{"Other"}); !errors.Is(err, ErrStructNotFound) {
+		t.Errorf("got %v, want %v", err, ErrStructNotFound)
+	}
+}
+
+// checkGenerated type checks the generated source with the models it was generated from
+func checkGenerated(t *testing.T, src []byte) {
+	t.Helper()
+
+	// The files are named in this directory, so the imports are found in the module
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for name, text := range map[string]string{"models.go": models, "models_filter.go": string(src)} {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), text, 0)
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("store", fset, files, nil); err != nil {
+		t.Errorf("generated code does not compile: %s\n%s", err, src)
+	}
+}
@@ -0,0 +1,66 @@
+// Command filtergen generates typed filterbuilder columns from Go structs with db tags.
+//
+// For each struct it writes a column handle for each tagged field, a Schema of the columns
+// and a search DTO skeleton that builds a Filter from the fields that are set:
+//
+//	//go:generate go run github.com/eaglebush/filterbuilder/v2/cmd/filtergen -type User,Order
+//
+// With go generate, the structs are read from the file with the directive and the code is written
+// next to it as <file>_filter.go. Otherwise pass the files to read as arguments.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	types := flag.String("type", "", "comma separated names of the structs to generate for. Defaults to all structs with db tags.")
+	output := flag.String("o", "", "output file. Defaults to <file>_filter.go next to the first input file.")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: filtergen [-type T1,T2] [-o file] [file.go ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		if gofile := os.Getenv("GOFILE"); gofile != "" {
+			files = []string{gofile}
+		}
+	}
+	if len(files) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var names []string
+	if *types != "" {
+		for _, n := range strings.Split(*types, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				names = append(names, n)
+			}
+		}
+	}
+	src, warnings, err := Generate(files, names)
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "filtergen:", w)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "filtergen:", err)
+		os.Exit(1)
+	}
+
+	out := *output
+	if out == "" {
+		out = strings.TrimSuffix(files[0], ".go") + "_filter.go"
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "filtergen:", err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "filtergen: wrote", filepath.Clean(out))
+}
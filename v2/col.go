@@ -0,0 +1,118 @@
+package filterbuilder
+
+// Col is a column handle whose values are checked by the compiler, such as
+//
+//	var Age = filterbuilder.Col[int]("age")
+//
+// Its methods return the usual filters, so Age.Eq(30) is EqRawPair("age", 30). Patterns are matched on a TextCol.
+type Col[T any] string
+
+// TextCol is a handle of a text column. Besides the filters of a Col[string], it can be matched with patterns:
+//
+//	var Name = filterbuilder.NewTextCol("name")
+type TextCol struct {
+	Col[string]
+}
+
+// NewTextCol creates a handle of a text column
+func NewTextCol(name string) TextCol {
+	return TextCol{Col: Col[string](name)}
+}
+
+// Like creates a Like filter on the column
+func (c TextCol) Like(pattern string) Lk {
+	return LkRawPair(string(c.Col), pattern)
+}
+
+// Name gets the name of the column
+func (c Col[T]) Name() string {
+	return string(c)
+}
+
+// Eq creates an equality filter on the column
+func (c Col[T]) Eq(value T) Eq {
+	return EqRawPair(string(c), value)
+}
+
+// Ne creates a not equality filter on the column
+func (c Col[T]) Ne(value T) Ne {
+	return NeRawPair(string(c), value)
+}
+
+// Lt creates a less than filter on the column
+func (c Col[T]) Lt(value T) Lt {
+	return LtRawPair(string(c), value)
+}
+
+// Lte creates a less than or equal filter on the column
+func (c Col[T]) Lte(value T) Lte {
+	return LteRawPair(string(c), value)
+}
+
+// Gt creates a greater than filter on the column
+func (c Col[T]) Gt(value T) Gt {
+	return GtRawPair(string(c), value)
+}
+
+// Gte creates a greater than or equal filter on the column
+func (c Col[T]) Gte(value T) Gte {
+	return GteRawPair(string(c), value)
+}
+
+// In creates an In filter on the column
+func (c Col[T]) In(values ...T) In {
+	return InRawPair(string(c), anys(values)...)
+}
+
+// NotIn creates a Not In filter on the column
+func (c Col[T]) NotIn(values ...T) Ni {
+	return NiRawPair(string(c), anys(values)...)
+}
+
+// Between creates a Between filter on the column
+func (c Col[T]) Between(from, to T) Bw {
+	return BwRawPair(string(c), from, to)
+}
+
+// NotBetween creates a Not Between filter on the column
+func (c Col[T]) NotBetween(from, to T) Nb {
+	return NbRawPair(string(c), from, to)
+}
+
+// Range creates a Range filter on the column including both bounds
+func (c Col[T]) Range(from, to T) Range {
+	return RangeRawPair(string(c), from, to)
+}
+
+// IsNull creates a filter matching the rows where the column is NULL
+func (c Col[T]) IsNull() Eq {
+	return EqRawPair(string(c), Null(true))
+}
+
+// IsNotNull creates a filter matching the rows where the column is not NULL
+func (c Col[T]) IsNotNull() Ne {
+	return NeRawPair(string(c), Null(true))
+}
+
+// EqCol creates a filter comparing the column with another column of the same type
+func (c Col[T]) EqCol(other Col[T]) Eq {
+	return EqColPair(string(c), string(other))
+}
+
+// Asc sorts by the column in ascending order
+func (c Col[T]) Asc() Sort {
+	return Sort{Column: string(c)}
+}
+
+// Desc sorts by the column in descending order
+func (c Col[T]) Desc() Sort {
+	return Sort{Column: string(c), Desc: true}
+}
+
+func anys[T any](values []T) []any {
+	out := make([]any, 0, len(values))
+	for _, v := range values {
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package filterbuilder
+
+import (
+	"encoding/hex"
+	"reflect"
+	"time"
+
+	ssd "github.com/shopspring/decimal"
+)
+
+// ArgConverter converts an argument before it is bound to its parameter.
+// A converter returns the value as it is when it does not apply to it.
+type ArgConverter func(v any) (any, error)
+
+// arg coerces the argument of a column to the type of the column,
+// then converts it with the converters of the column, the filter and the dialect, in that order.
+// NULL values and column references are not arguments and are left as they are.
+func (bs *buildState) arg(name string, v any) (any, error) {
+	switch v.(type) {
+	case nil, Null, ColumnRef:
+		return v, nil
+	}
+
+	var c Column
+	if name != "" && bs.schema != nil {
+		_, c, _ = bs.schema.lookup(name)
+	}
+	v, err := bs.coerce(c, v)
+	if err != nil {
+		return nil, err
+	}
+	if c.Convert != nil {
+		if v, err = c.Convert(v); err != nil {
+			return nil, err
+		}
+	}
+	for _, cv := range bs.converters {
+		if v, err = cv(v); err != nil {
+			return nil, err
+		}
+	}
+	if bs.dialect != nil {
+		for _, cv := range bs.dialect.Converters {
+			if v, err = cv(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+// rowArgs converts the arguments of a row, one for each of the columns
+func (bs *buildState) rowArgs(names []string, vals []any) error {
+	for i, v := range vals {
+		cv, err := bs.arg(names[i], v)
+		if err != nil {
+			return err
+		}
+		vals[i] = cv
+	}
+	return nil
+}
+
+// BoolToInt converts booleans to 1 and 0, for databases without a boolean type
+func BoolToInt(v any) (any, error) {
+	if b, ok := v.(bool); ok {
+		if b {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return v, nil
+}
+
+// DecimalToString converts decimals to their exact string form, for drivers that cannot bind them
+func DecimalToString(v any) (any, error) {
+	if d, ok := v.(ssd.Decimal); ok {
+		return d.String(), nil
+	}
+	return v, nil
+}
+
+// TimeToUTC converts times to UTC, for columns that store times without a zone
+func TimeToUTC(v any) (any, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC(), nil
+	}
+	return v, nil
+}
+
+// UUIDToString converts 16 byte UUIDs to their canonical string form, such as 123e4567-e89b-12d3-a456-426614174000.
+// It applies to byte slices and arrays of 16 bytes, which includes most UUID types.
+func UUIDToString(v any) (any, error) {
+	var b []byte
+	switch t := v.(type) {
+	case []byte:
+		b = t
+	case [16]byte:
+		b = t[:]
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Array || rv.Len() != 16 || rv.Type().Elem().Kind() != reflect.Uint8 {
+			return v, nil
+		}
+		b = make([]byte, 16)
+		reflect.Copy(reflect.ValueOf(b), rv)
+	}
+	if len(b) != 16 {
+		return v, nil
+	}
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+}
+
+// EnumToInt converts values of named integer types, such as enums declared with iota, to int64 or uint64
+func EnumToInt(v any) (any, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Type().PkgPath() == "" {
+		return v, nil
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+	}
+	return v, nil
+}
@@ -0,0 +1,156 @@
+package filterbuilder
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	ssd "github.com/shopspring/decimal"
+)
+
+// cursor is the signed content of a keyset pagination cursor
+type cursor struct {
+	Keys   []SeekKey     `json:"k"`
+	Values []cursorValue `json:"v"`
+}
+
+// cursorValue is a key value with its type, so that it decodes into the same type
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+// EncodeCursor encodes the key values of the last row of a page into an opaque cursor for the next page.
+// The cursor is signed with secret using HMAC-SHA256 so clients cannot tamper with it, and is bound to the keys.
+// The secret must not be empty.
+//
+// The values can be strings, integers, floats, bools, time.Time and decimals.
+// Integers decode as int64 and unsigned integers as uint64.
+func EncodeCursor(secret []byte, keys []SeekKey, values ...any) (string, error) {
+	if len(secret) == 0 {
+		return "", ErrCursorSecret
+	}
+	if len(keys) == 0 || len(keys) != len(values) {
+		return "", ErrSeekSize
+	}
+	c := cursor{
+		Keys:   keys,
+		Values: make([]cursorValue, 0, len(values)),
+	}
+	for _, v := range values {
+		cv, err := encodeCursorValue(v)
+		if err != nil {
+			return "", err
+		}
+		c.Values = append(c.Values, cv)
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(signCursor(secret, payload)), nil
+}
+
+// DecodeCursor verifies a cursor made by EncodeCursor for the same keys and returns the Seek for the next page
+func DecodeCursor(secret []byte, keys []SeekKey, cur string) (Seek, error) {
+	if len(secret) == 0 {
+		return Seek{}, ErrCursorSecret
+	}
+	enc := base64.RawURLEncoding
+	p, s, ok := strings.Cut(cur, ".")
+	if !ok {
+		return Seek{}, ErrInvalidCursor
+	}
+	payload, err := enc.DecodeString(p)
+	if err != nil {
+		return Seek{}, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(s)
+	if err != nil || !hmac.Equal(sig, signCursor(secret, payload)) {
+		return Seek{}, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return Seek{}, ErrInvalidCursor
+	}
+	if !reflect.DeepEqual(c.Keys, keys) || len(c.Values) != len(keys) {
+		return Seek{}, ErrInvalidCursor
+	}
+	values := make([]any, 0, len(c.Values))
+	for _, cv := range c.Values {
+		v, err := decodeCursorValue(cv)
+		if err != nil {
+			return Seek{}, err
+		}
+		values = append(values, v)
+	}
+	return SeekAfter(keys, values...), nil
+}
+
+func signCursor(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func encodeCursorValue(value any) (cursorValue, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		return encodeCursorValue(rv.Elem().Interface())
+	}
+	switch t := value.(type) {
+	case string:
+		return cursorValue{Type: "s", Value: t}, nil
+	case bool:
+		return cursorValue{Type: "b", Value: strconv.FormatBool(t)}, nil
+	case time.Time:
+		return cursorValue{Type: "t", Value: t.Format(time.RFC3339Nano)}, nil
+	case ssd.Decimal:
+		return cursorValue{Type: "d", Value: t.String()}, nil
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: "i", Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: "u", Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return cursorValue{Type: "f", Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	}
+	return cursorValue{}, ErrCursorValueType
+}
+
+func decodeCursorValue(cv cursorValue) (any, error) {
+	var (
+		v   any
+		err error
+	)
+	switch cv.Type {
+	case "s":
+		return cv.Value, nil
+	case "b":
+		v, err = strconv.ParseBool(cv.Value)
+	case "t":
+		v, err = time.Parse(time.RFC3339Nano, cv.Value)
+	case "d":
+		v, err = ssd.NewFromString(cv.Value)
+	case "i":
+		v, err = strconv.ParseInt(cv.Value, 10, 64)
+	case "u":
+		v, err = strconv.ParseUint(cv.Value, 10, 64)
+	case "f":
+		v, err = strconv.ParseFloat(cv.Value, 64)
+	default:
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return v, nil
+}
@@ -0,0 +1,106 @@
+package filterbuilder
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OnDate creates a Range over the calendar day of date in loc.
+// The range includes the start of the day and excludes the start of the next day.
+// When loc is nil, the location of date is used.
+func OnDate(column string, date time.Time, loc *time.Location) Range {
+	start := startOfDay(inLocation(date, loc))
+	return RangeRawPair(column, start, start.AddDate(0, 0, 1)).Exclusive(false, true)
+}
+
+// InMonth creates a Range over a calendar month in loc.
+// The range includes the start of the month and excludes the start of the next month.
+// When loc is nil, time.Local is used.
+func InMonth(column string, year int, month time.Month, loc *time.Location) Range {
+	if loc == nil {
+		loc = time.Local
+	}
+	start := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return RangeRawPair(column, start, start.AddDate(0, 1, 0)).Exclusive(false, true)
+}
+
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// resolveRelative resolves a relative time expression against now.
+//
+// An expression is an anchor followed by any number of offsets, such as "now-7d" or "startOfMonth-1M+2w".
+// The anchors are now, today, startOfDay, startOfWeek (Monday), startOfMonth and startOfYear.
+// The offset units are s (second), m (minute), h (hour), d (day), w (week), M (month) and y (year).
+func resolveRelative(expr string, now time.Time) (time.Time, error) {
+	expr = strings.TrimSpace(expr)
+	i := strings.IndexAny(expr, "+-")
+	if i < 0 {
+		i = len(expr)
+	}
+
+	var t time.Time
+	switch anchor := expr[:i]; {
+	case strings.EqualFold(anchor, "now"):
+		t = now
+	case strings.EqualFold(anchor, "today"), strings.EqualFold(anchor, "startOfDay"):
+		t = startOfDay(now)
+	case strings.EqualFold(anchor, "startOfWeek"):
+		t = startOfDay(now)
+		t = t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	case strings.EqualFold(anchor, "startOfMonth"):
+		t = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	case strings.EqualFold(anchor, "startOfYear"):
+		t = time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	default:
+		return t, ErrInvalidRelativeTime
+	}
+
+	for rest := expr[i:]; rest != ""; {
+		sign := 1
+		if rest[0] == '-' {
+			sign = -1
+		}
+		j := 1
+		for j < len(rest) && rest[j] >= '0' && rest[j] <= '9' {
+			j++
+		}
+		if j == 1 || j == len(rest) {
+			return t, ErrInvalidRelativeTime
+		}
+		n, err := strconv.Atoi(rest[1:j])
+		if err != nil {
+			return t, ErrInvalidRelativeTime
+		}
+		n *= sign
+		switch rest[j] {
+		case 's':
+			t = t.Add(time.Duration(n) * time.Second)
+		case 'm':
+			t = t.Add(time.Duration(n) * time.Minute)
+		case 'h':
+			t = t.Add(time.Duration(n) * time.Hour)
+		case 'd':
+			t = t.AddDate(0, 0, n)
+		case 'w':
+			t = t.AddDate(0, 0, 7*n)
+		case 'M':
+			t = t.AddDate(0, n, 0)
+		case 'y':
+			t = t.AddDate(n, 0, 0)
+		default:
+			return t, ErrInvalidRelativeTime
+		}
+		rest = rest[j+1:]
+	}
+	return t, nil
+}
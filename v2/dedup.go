@@ -0,0 +1,90 @@
+package filterbuilder
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// numbered tells if the placeholders are numbered, so a parameter can be referenced more than once
+func (bs *buildState) numbered() bool {
+	return bs.names == nil && bs.inSeq && bs.ph != "?"
+}
+
+// dedupArgs makes identical arguments share one numbered placeholder.
+// The placeholders after start are renumbered in the query fragments and the repeated arguments are removed.
+// Arguments that are not comparable, such as the lists of InArray, are never shared.
+func dedupArgs(qry []string, args []any, ph string, start int, d *Dialect) ([]string, []any) {
+	index := make(map[any]int, len(args))
+	renum := make([]int, len(args))
+	uniq := make([]any, 0, len(args))
+	for i, a := range args {
+		cmp := a != nil && reflect.ValueOf(a).Comparable()
+		if cmp {
+			if n, ok := index[a]; ok {
+				renum[i] = n
+				continue
+			}
+		}
+		uniq = append(uniq, a)
+		renum[i] = start + len(uniq)
+		if cmp {
+			index[a] = renum[i]
+		}
+	}
+	if len(uniq) == len(args) {
+		return qry, args
+	}
+
+	out := make([]string, 0, len(qry))
+	for _, q := range qry {
+		out = append(out, renumber(q, ph, d, func(n int) int {
+			if n <= start || n-start > len(renum) {
+				return n
+			}
+			return renum[n-start-1]
+		}))
+	}
+	return out, uniq
+}
+
+// renumber replaces the number of every numbered placeholder outside of string literals, quoted identifiers and comments
+func renumber(qry, ph string, d *Dialect, fn func(int) int) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(qry); {
+		if j := skipQuoted(qry, i, d); j > i {
+			sb.WriteString(qry[i:j])
+			i = j
+			continue
+		}
+		if !strings.HasPrefix(qry[i:], ph) {
+			sb.WriteByte(qry[i])
+			i++
+			continue
+		}
+		j := i + len(ph)
+		for j < len(qry) && qry[j] >= '0' && qry[j] <= '9' {
+			j++
+		}
+		n, err := strconv.Atoi(qry[i+len(ph) : j])
+		if err != nil {
+			sb.WriteString(qry[i:j])
+			i = j
+			continue
+		}
+		sb.WriteString(ph + strconv.Itoa(fn(n)))
+		i = j
+	}
+	return sb.String()
+}
+
+// dedup makes identical arguments share one placeholder when DedupArgs is set and the placeholders are numbered.
+// The Offset of the filter is moved back to the last placeholder left.
+func (fb *Filter) dedup(bs *buildState, qry []string, args []any, start int) ([]string, []any) {
+	if !fb.DedupArgs || !bs.numbered() {
+		return qry, args
+	}
+	qry, args = dedupArgs(qry, args, bs.ph, start, bs.dialect)
+	fb.Offset = start + len(args)
+	return qry, args
+}
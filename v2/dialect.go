@@ -0,0 +1,161 @@
+package filterbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// InStrategy selects how IN and NOT IN lists are rendered
+type InStrategy int
+
+const (
+	InExpand InStrategy = iota // One placeholder per value: col IN (?,?,?)
+	InArray                    // A single array parameter: col = ANY($1)
+	InTable                    // A single parameter expanded into a table by the database: col IN (SELECT value FROM OPENJSON(@p1))
+	InChunk                    // One placeholder per value, split into chunks: (col IN (...) OR col IN (...))
+)
+
+// DefaultChunkSize is the number of values per chunk when a Dialect does not set one
+const DefaultChunkSize = 1000
+
+// Dialect describes how a database renders parameters and membership lists
+type Dialect struct {
+	Name             string                   // Name of the dialect
+	Placeholder      string                   // Parameter place holder
+	InSequence       bool                     // Parameter place holders would be numbered in sequence
+	MaxParams        int                      // Maximum number of parameters in a statement. Zero means no limit.
+	RowValues        bool                     // Dialect has row values such as (a, b) IN ((?,?))
+	RowCompare       bool                     // Dialect compares row values by order, such as (a, b) > (?,?)
+	NullsOrdering    bool                     // Dialect has NULLS FIRST and NULLS LAST in ORDER BY
+	InStrategy       InStrategy               // How IN and NOT IN lists are rendered
+	Paging           Paging                   // How a SELECT statement limits its rows
+	DefaultOrder     string                   // ORDER BY term of a paged SELECT statement without a sort, for dialects that require one
+	ChunkSize        int                      // Number of values per chunk for InChunk. Zero uses DefaultChunkSize.
+	TableExpr        string                   // Table expression for InTable. The %s verb is replaced with the placeholder.
+	TableArg         func([]any) (any, error) // Converts the list into the single parameter of InTable. Defaults to a JSON array.
+	ArrayArg         func([]any) any          // Converts the list into the single parameter of InArray. Defaults to the list itself.
+	BoolLiterals     bool                     // Dialect has TRUE and FALSE literals. Otherwise booleans are inlined as 1 and 0.
+	BackslashEscapes bool                     // Backslashes in string literals are escapes and are doubled when inlined
+	StringPrefix     string                   // Prefix of inlined string literals, such as N for Unicode strings
+	BytesFormat      string                   // Format of inlined byte literals. The %s verb is replaced with the hexadecimal digits. Defaults to X'%s'.
+	TimeLayout       string                   // Layout of inlined time literals. Defaults to 2006-01-02 15:04:05.999999999Z07:00.
+	TimePrefix       string                   // Prefix of inlined time literals, such as TIMESTAMP
+	Converters       []ArgConverter           // Convert every argument bound on the dialect
+	BracketQuotes    bool                     // Identifiers can be quoted in square brackets, such as [order]. Otherwise brackets are array subscripts.
+}
+
+// Predefined dialects
+var (
+	MySQL = Dialect{
+		Name:             "mysql",
+		Placeholder:      "?",
+		MaxParams:        65535,
+		RowValues:        true,
+		RowCompare:       true,
+		TableExpr:        "SELECT v FROM JSON_TABLE(%s, '$[*]' COLUMNS(v VARCHAR(255) PATH '$')) AS jt",
+		BoolLiterals:     true,
+		BackslashEscapes: true,
+		TimeLayout:       "2006-01-02 15:04:05.999999",
+	}
+	Postgres = Dialect{
+		Name:          "postgres",
+		Placeholder:   "$",
+		InSequence:    true,
+		MaxParams:     65535,
+		RowValues:     true,
+		RowCompare:    true,
+		NullsOrdering: true,
+		TableExpr:     "SELECT jsonb_array_elements_text(%s::jsonb)",
+		BoolLiterals:  true,
+		BytesFormat:   `'\x%s'`,
+	}
+	SQLServer = Dialect{
+		Name:          "sqlserver",
+		Placeholder:   "@p",
+		InSequence:    true,
+		MaxParams:     2100,
+		Paging:        PagingOffsetFetch,
+		DefaultOrder:  "(SELECT NULL)",
+		TableExpr:     "SELECT value FROM OPENJSON(%s)",
+		StringPrefix:  "N",
+		BytesFormat:   "0x%s",
+		TimeLayout:    "2006-01-02T15:04:05.9999999",
+		BracketQuotes: true,
+	}
+	SQLite = Dialect{
+		Name:          "sqlite",
+		Placeholder:   "?",
+		MaxParams:     32766,
+		RowValues:     true,
+		NullsOrdering: true,
+		TableExpr:     "SELECT value FROM json_each(%s)",
+		BoolLiterals:  true,
+		TimeLayout:    "2006-01-02 15:04:05.999999999-07:00",
+		BracketQuotes: true,
+	}
+	Oracle = Dialect{
+		Name:          "oracle",
+		Placeholder:   ":",
+		InSequence:    true,
+		MaxParams:     65535,
+		RowValues:     true,
+		NullsOrdering: true,
+		InStrategy:    InChunk,
+		Paging:        PagingOffsetFetch,
+		ChunkSize:     1000,
+		TableExpr:     "SELECT v FROM JSON_TABLE(%s, '$[*]' COLUMNS(v VARCHAR2(4000) PATH '$'))",
+		BytesFormat:   "HEXTORAW('%s')",
+		TimeLayout:    "2006-01-02 15:04:05.999999999",
+		TimePrefix:    "TIMESTAMP ",
+		Converters:    []ArgConverter{BoolToInt},
+	}
+)
+
+func (d *Dialect) chunkSize() int {
+	if d == nil || d.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+	return d.ChunkSize
+}
+
+// bracketQuotes tells if square brackets quote identifiers
+func (d *Dialect) bracketQuotes() bool {
+	return d != nil && d.BracketQuotes
+}
+
+// backslashEscapes tells if backslashes escape characters in string literals
+func (d *Dialect) backslashEscapes() bool {
+	return d != nil && d.BackslashEscapes
+}
+
+func (d *Dialect) paging() Paging {
+	if d == nil {
+		return PagingLimitOffset
+	}
+	return d.Paging
+}
+
+func (d *Dialect) arrayArg(values []any) any {
+	if d.ArrayArg != nil {
+		return d.ArrayArg(values)
+	}
+	return values
+}
+
+func (d *Dialect) tableArg(values []any) (any, error) {
+	if d.TableArg != nil {
+		return d.TableArg(values)
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *Dialect) tableExpr(ph string) (string, error) {
+	if d.TableExpr == "" {
+		return "", ErrNoTableExpr
+	}
+	return fmt.Sprintf(d.TableExpr, ph), nil
+}
@@ -29,8 +29,24 @@ func EqDataPair(column string, value any) Eq {
 	}
 }
 
+// EqColPair simplifies column to column Eq pair.
+// Pairs compares the column with the other column instead of a value.
+func EqColPair(column string, other string) Eq {
+	return Eq{
+		Column: column,
+		Value: Value{
+			Src: other,
+			Col: true,
+		},
+	}
+}
+
 func (f Eq) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildPair(f, data, "=", ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Eq) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildPair(f, data, "=", bs, offset)
 }
 
 func (f Eq) GetPair() any {
@@ -0,0 +1,120 @@
+package filterbuilder
+
+import "strings"
+
+// Relation describes a table related to the rows being filtered
+type Relation struct {
+	Table  string // Related table with its alias, e.g. "lines l"
+	On     string // Correlation condition with the outer rows, e.g. "l.order_id = o.id"
+	Schema Schema // Columns of the related table that can be referenced. When nil, the Schema of the Filter is used.
+}
+
+// Relations is a registry of related tables keyed by their public names
+type Relations map[string]Relation
+
+// Exists is EXISTS in SQL. It matches the rows that have at least one related row satisfying the Where filter.
+//
+// The related table is either a registered Relation or, from Go only, a Table and its On condition.
+type Exists struct {
+	Relation string  `json:"relation,omitempty"` // Name of the relation in the Relations of the Filter
+	Table    string  `json:"-"`                  // Related table with its alias when no Relation is set
+	On       string  `json:"-"`                  // Correlation condition when no Relation is set
+	Where    *Filter `json:"where,omitempty"`    // Filter of the related rows. Data defaults to the Data of the outer filter.
+}
+
+// NotExists is NOT EXISTS in SQL. It matches the rows that have no related row satisfying the Where filter.
+type NotExists Exists
+
+func (e Exists) GetPair() any {
+	return e
+}
+
+func (e Exists) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return e.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (e Exists) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildExists(e, "EXISTS", bs, data, offset)
+}
+
+func (e NotExists) GetPair() any {
+	return e
+}
+
+func (e NotExists) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return e.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (e NotExists) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildExists(Exists(e), "NOT EXISTS", bs, data, offset)
+}
+
+func buildExists(e Exists, operator string, bs *buildState, data any, offset int) (string, []any, int, error) {
+	rel, err := bs.relation(e.Relation, e.Table, e.On)
+	if err != nil {
+		return "", nil, offset, err
+	}
+
+	conds := make([]string, 0, 10)
+	if rel.On != "" {
+		conds = append(conds, rel.On)
+	}
+
+	var (
+		sql  []string
+		args = []any{}
+	)
+	if e.Where != nil {
+		sql, args, offset, err = buildSubfilter(e.Where, rel.Schema, bs, data, offset)
+		if err != nil {
+			return "", nil, offset, err
+		}
+		conds = append(conds, sql...)
+	}
+
+	qry := operator + " (SELECT 1 FROM " + rel.Table
+	if len(conds) > 0 {
+		qry += " WHERE " + strings.Join(conds, " AND ")
+	}
+	return qry + ")", args, offset, nil
+}
+
+// relation gets a registered relation by name, or the relation of table when name is empty
+func (bs *buildState) relation(name, table, on string) (Relation, error) {
+	rel := Relation{
+		Table: table,
+		On:    on,
+	}
+	if name != "" {
+		var ok bool
+		if rel, ok = bs.relations[name]; !ok {
+			return rel, ErrRelationNotFound
+		}
+	}
+	if rel.Table == "" {
+		return rel, ErrRelationNotFound
+	}
+	return rel, nil
+}
+
+// buildSubfilter builds a filter nested in another with the settings of the outer filter,
+// numbering parameters after offset. The Data of the outer filter is used when the nested filter has none.
+// The columns are checked against the first Schema set among the nested filter, schema and the outer filter.
+func buildSubfilter(f *Filter, schema Schema, bs *buildState, data any, offset int) ([]string, []any, int, error) {
+	inner := *f
+	inner.Offset = offset
+	if inner.Data == nil {
+		inner.Data = data
+	}
+
+	ibs := *bs
+	switch {
+	case inner.Schema != nil:
+		ibs.schema = inner.Schema
+	case schema != nil:
+		ibs.schema = schema
+	}
+
+	sql, args, err := inner.build(&ibs)
+	return sql, args, inner.Offset, err
+}
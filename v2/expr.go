@@ -0,0 +1,65 @@
+package filterbuilder
+
+// Expr is a trusted SQL expression with ? markers for its arguments, for the cases not covered by the other filters:
+//
+//	NewExpr("ST_DWithin(geom, ST_MakePoint(?, ?), ?)", lng, lat, meters)
+//
+// The markers are renumbered into the placeholder style and offset of the filter, so an Expr mixes with the other
+// filters inside Or, Group and Not. A ? outside of string literals and comments is a marker; write ?? for a literal ?.
+// A literal ? cannot be told from a marker where the placeholder is a plain ?, as in MySQL and SQLite,
+// so ?? fails there with ErrExprEscapedMarker.
+//
+// The SQL is written into the query as is. An Expr can only be constructed from Go and never from JSON,
+// and the Exprs function of a Filter lists every Expr in it for review.
+type Expr struct {
+	SQL  string // SQL expression with ? markers
+	Args []any  // Arguments of the markers, in order
+}
+
+// NewExpr creates a trusted SQL expression with its arguments
+func NewExpr(sql string, args ...any) Expr {
+	return Expr{
+		SQL:  sql,
+		Args: args,
+	}
+}
+
+func (e Expr) GetPair() any {
+	return e
+}
+
+func (e Expr) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return e.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (e Expr) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	var (
+		ph string
+		n  int
+	)
+	if bs.ph == "?" && bs.names == nil && countMarkers(e.SQL, "??", bs.dialect) > 0 {
+		return "", nil, offset, ErrExprEscapedMarker
+	}
+	qry := replaceMarkers(e.SQL, bs.dialect, func() string {
+		n++
+		ph, offset = bs.param("expr", offset)
+		return ph
+	})
+	if n != len(e.Args) {
+		return "", nil, offset, ErrExprArgCount
+	}
+	args := make([]any, 0, len(e.Args))
+	for _, a := range e.Args {
+		v, err := bs.arg("", a)
+		if err != nil {
+			return "", nil, offset, err
+		}
+		args = append(args, v)
+	}
+	return qry, args, offset, nil
+}
+
+// UnmarshalJSON refuses to decode an Expr. An Expr must only come from trusted Go code.
+func (e *Expr) UnmarshalJSON([]byte) error {
+	return ErrFiltererNotSerializable
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Filterer interface {
@@ -13,139 +14,436 @@ type Filterer interface {
 
 // Filter - the filter struct
 type Filter struct {
-	Data           any     `json:"data,omitempty"`
-	Eq             []Eq    `json:"eq,omitempty"`               // Equality pairs
-	Lt             []Lt    `json:"lt,omitempty"`               // Less than pairs
-	Lte            []Lte   `json:"lte,omitempty"`              // Less than equal pairs
-	Gt             []Gt    `json:"gt,omitempty"`               // Greater pairs
-	Gte            []Gte   `json:"gte,omitempty"`              // Greater than equal pair
-	Group          []Group `json:"group,omitempty"`            // Group, a utility of grouping main comparison filters
-	Ne             []Ne    `json:"ne,omitempty"`               // Not equality pairs
-	Lk             []Lk    `json:"lk,omitempty"`               // Like pairs
-	Or             []Or    `json:"or,omitempty"`               // Or pairs. These should be any of the definite filter
-	In             []In    `json:"in,omitempty"`               // In column pair.
-	NotIn          []Ni    `json:"not_in,omitempty"`           // Not In column pair
-	Between        []Bw    `json:"between,omitempty"`          // Between column pair
-	Placeholder    string  `json:"placeholder,omitempty"`      // Parameter place holder
-	InSequence     bool    `json:"in_sequence,omitempty"`      // Parameter place holders would be numbered in sequence
-	Offset         int     `json:"offset,omitempty"`           // Sets the start of parameter number
-	AllowNoFilters bool    `json:"allow_no_filters,omitempty"` // Allow no filter upon building
-}
-
-func buildPair(f Filterer, srcData any, operator, ph string, inSeq bool, offset int) (string, any, int, error) {
+	Data           any              `json:"data,omitempty"`
+	Eq             []Eq             `json:"eq,omitempty"`                // Equality pairs
+	Lt             []Lt             `json:"lt,omitempty"`                // Less than pairs
+	Lte            []Lte            `json:"lte,omitempty"`               // Less than equal pairs
+	Gt             []Gt             `json:"gt,omitempty"`                // Greater pairs
+	Gte            []Gte            `json:"gte,omitempty"`               // Greater than equal pair
+	Group          []Group          `json:"group,omitempty"`             // Group, a utility of grouping main comparison filters
+	Ne             []Ne             `json:"ne,omitempty"`                // Not equality pairs
+	Lk             []Lk             `json:"lk,omitempty"`                // Like pairs
+	Or             []Or             `json:"or,omitempty"`                // Or pairs. These should be any of the definite filter
+	In             []In             `json:"in,omitempty"`                // In column pair.
+	NotIn          []Ni             `json:"not_in,omitempty"`            // Not In column pair
+	Between        []Bw             `json:"between,omitempty"`           // Between column pair
+	NotBetween     []Nb             `json:"not_between,omitempty"`       // Not Between column pair
+	Range          []Range          `json:"range,omitempty"`             // Range with optional and exclusive bounds
+	Not            []Not            `json:"not,omitempty"`               // Negated filters
+	TupleIn        []TupleIn        `json:"tuple_in,omitempty"`          // In pairs over several columns
+	Seek           []Seek           `json:"-"`                           // Keyset pagination predicates. Never read from JSON: set them from DecodeCursor.
+	Exists         []Exists         `json:"exists,omitempty"`            // Exists filters on related tables
+	NotExists      []NotExists      `json:"not_exists,omitempty"`        // Not Exists filters on related tables
+	InQuery        []InQuery        `json:"in_query,omitempty"`          // In subquery pairs
+	NotInQuery     []NotInQuery     `json:"not_in_query,omitempty"`      // Not In subquery pairs
+	Expr           []Expr           `json:"-"`                           // Trusted SQL expressions. Never read from JSON.
+	Sort           []Sort           `json:"sort,omitempty"`              // Sort order of the rows
+	Having         *Filter          `json:"having,omitempty"`            // Filter of the HAVING clause over the Aggregates
+	Placeholder    string           `json:"placeholder,omitempty"`       // Parameter place holder
+	InSequence     bool             `json:"in_sequence,omitempty"`       // Parameter place holders would be numbered in sequence
+	Offset         int              `json:"offset,omitempty"`            // Sets the start of parameter number
+	AllowNoFilters bool             `json:"allow_no_filters,omitempty"`  // Allow no filter upon building
+	Dialect        *Dialect         `json:"-"`                           // Database dialect. Sets the IN strategy and parameter limit.
+	DropEmptyNotIn bool             `json:"drop_empty_not_in,omitempty"` // Drop a NOT IN with an empty list instead of rendering an always true predicate
+	Clock          func() time.Time `json:"-"`                           // Clock to resolve relative time expressions. Defaults to time.Now.
+	Location       *time.Location   `json:"-"`                           // Time zone of resolved relative time expressions. Defaults to time.Local.
+	Schema         Schema           `json:"-"`                           // Registry of the columns that can be referenced
+	PushDownNot    bool             `json:"push_down_not,omitempty"`     // Apply negations to the negated filters, so Not{Eq} renders <> and Not{In} renders NOT IN
+	Relations      Relations        `json:"-"`                           // Registry of the relations that Exists filters can reference
+	Aggregates     Schema           `json:"-"`                           // Registry of the aggregate expressions that the Having filter can reference
+	Required       []string         `json:"-"`                           // Columns that an UPDATE or DELETE filter must constrain
+	DedupArgs      bool             `json:"dedup_args,omitempty"`        // Identical arguments share one numbered placeholder
+	BaseDialect    *Dialect         `json:"-"`                           // Dialect of the SQL string given to Weld. When set, its placeholders are rebound to those of the filter.
+	Converters     []ArgConverter   `json:"-"`                           // Convert every argument of the filter, after the converters of the columns
+}
+
+// buildState carries the settings of a Filter down to the Filterer being built
+type buildState struct {
+	ph             string
+	inSeq          bool
+	dialect        *Dialect
+	dropEmptyNotIn bool
+	clock          func() time.Time
+	loc            *time.Location
+	schema         Schema
+	pushDownNot    bool
+	relations      Relations
+	names          *paramNames
+	converters     []ArgConverter
+}
+
+// stateBuilder is implemented by the filter types of this package
+// so they can be built with all the settings of a Filter
+type stateBuilder interface {
+	build(bs *buildState, data any, offset int) (string, any, int, error)
+}
+
+func newBuildState(ph string, inSeq bool) *buildState {
+	return &buildState{
+		ph:    strings.TrimSpace(ph),
+		inSeq: inSeq,
+	}
+}
+
+// param advances the offset and returns the placeholder for it.
+// In named mode the placeholder is named after the column name.
+func (bs *buildState) param(name string, offset int) (string, int) {
+	offset++
+	if bs.names != nil {
+		return bs.ph + bs.names.next(name), offset
+	}
+	ph := bs.ph
+	if bs.inSeq && ph != "?" {
+		ph += strconv.Itoa(offset)
+	}
+	return ph, offset
+}
+
+// params returns a comma separated list of n placeholders
+func (bs *buildState) params(name string, n, offset int) (string, int) {
+	var ph string
+	phs := make([]string, 0, n)
+	for range n {
+		ph, offset = bs.param(name, offset)
+		phs = append(phs, ph)
+	}
+	return strings.Join(phs, ","), offset
+}
+
+// rowParams returns a comma separated list of placeholders, one for each of the columns
+func (bs *buildState) rowParams(names []string, offset int) (string, int) {
+	var ph string
+	phs := make([]string, 0, len(names))
+	for _, name := range names {
+		ph, offset = bs.param(name, offset)
+		phs = append(phs, ph)
+	}
+	return strings.Join(phs, ","), offset
+}
+
+// column gets the SQL expression of a column, checked against the schema
+func (bs *buildState) column(name string) (string, error) {
+	return bs.schema.column(name)
+}
+
+// value gets the value of p. Relative time expressions are resolved against the clock.
+// Column references are resolved to a ColumnRef. They are inlined in the query,
+// so they must name a column of the schema.
+func (bs *buildState) value(data any, p Value) (any, error) {
+	if p.Col {
+		name, ok := p.Src.(string)
+		if !ok {
+			return nil, ErrInvalidFieldName
+		}
+		if bs.schema == nil {
+			return nil, ErrSchemaRequired
+		}
+		col, err := bs.column(name)
+		if err != nil {
+			return nil, err
+		}
+		return ColumnRef(col), nil
+	}
+	if p.Rel {
+		expr, ok := p.Src.(string)
+		if !ok {
+			return nil, ErrInvalidRelativeTime
+		}
+		return resolveRelative(expr, bs.now())
+	}
+	return getFilterValue(data, p)
+}
+
+func (bs *buildState) values(data any, p []Value) ([]any, error) {
+	args := make([]any, 0, len(p))
+	for _, mv := range p {
+		v, err := bs.value(data, mv)
+		if err != nil {
+			return args, err
+		}
+		args = append(args, v)
+	}
+	return args, nil
+}
+
+// now gets the current time from the clock in the location of the build
+func (bs *buildState) now() time.Time {
+	now := time.Now
+	if bs.clock != nil {
+		now = bs.clock
+	}
+	loc := time.Local
+	if bs.loc != nil {
+		loc = bs.loc
+	}
+	return now().In(loc)
+}
+
+// rowValues tells if the dialect has row values such as (a, b) IN ((?,?))
+func (bs *buildState) rowValues() bool {
+	return bs.dialect == nil || bs.dialect.RowValues
+}
+
+// rowCompare tells if the dialect compares row values by order such as (a, b) > (?, ?)
+func (bs *buildState) rowCompare() bool {
+	return bs.dialect == nil || bs.dialect.RowCompare
+}
+
+// nullsOrdering tells if the dialect has NULLS FIRST and NULLS LAST
+func (bs *buildState) nullsOrdering() bool {
+	return bs.dialect == nil || bs.dialect.NullsOrdering
+}
+
+// andOnly gets the settings for the terms of an OR or a NOT,
+// where terms that are dropped in AND context must be rendered instead
+func (bs *buildState) andOnly() *buildState {
+	if !bs.dropEmptyNotIn {
+		return bs
+	}
+	ibs := *bs
+	ibs.dropEmptyNotIn = false
+	return &ibs
+}
+
+func (bs *buildState) inStrategy() InStrategy {
+	if bs.dialect == nil {
+		return InExpand
+	}
+	return bs.dialect.InStrategy
+}
+
+// buildFilterer builds f with the settings in bs.
+// Filterer not from this package are built with their Build function.
+func buildFilterer(f Filterer, bs *buildState, data any, offset int) (string, any, int, error) {
+	if sb, ok := f.(stateBuilder); ok {
+		return sb.build(bs, data, offset)
+	}
+	return f.Build(data, bs.ph, bs.inSeq, offset)
+}
+
+func buildPair(f Filterer, srcData any, operator string, bs *buildState, offset int) (string, any, int, error) {
 	var (
-		qry string
-		v   any
-		err error
+		qry, ph string
+		v       any
+		err     error
 	)
 
 	p := f.GetPair()
 	vOp := reflect.ValueOf(p)
-	col := vOp.FieldByName("Column").String()
+	name := vOp.FieldByName("Column").String()
+	col, err := bs.column(name)
+	if err != nil {
+		return qry, v, offset, err
+	}
 	val := vOp.FieldByName("Value").Interface().(Value)
 
-	v, err = getFilterValue(srcData, val)
+	v, err = bs.value(srcData, val)
+	if err == nil {
+		v, err = bs.arg(name, v)
+	}
 	if err != nil {
 		return qry, v, offset, err
 	}
 	if v == nil {
 		return qry, v, offset, err
 	}
-	switch v.(type) {
+	switch t := v.(type) {
 	case Null:
-		qry = col + " IS NULL"
+		// IS NULL takes no parameter, so the value is not an argument
+		if operator == "<>" {
+			return col + " IS NOT NULL", nil, offset, nil
+		}
+		return col + " IS NULL", nil, offset, nil
+	case ColumnRef:
+		return col + " " + operator + " " + string(t), nil, offset, nil
 	default:
-		offset++
-		ph = strings.TrimSpace(ph)
+		ph, offset = bs.param(col, offset)
 		qry = col + " " + operator + " " + ph
-		if inSeq && ph != "?" {
-			qry += strconv.Itoa(offset)
-		}
 	}
 	return qry, v, offset, nil
 }
 
-func buildMembershipPair(f Filterer, srcData any, operator, ph string, inSeq bool, offset int) (string, []any, int, error) {
+func buildMembershipPair(f Filterer, srcData any, operator string, bs *buildState, offset int) (string, []any, int, error) {
 	var (
-		qry, cma string
-		v        any
-		args     []any
-		err      error
+		qry     string
+		v       any
+		args    []any
+		err     error
+		hasNull bool
 	)
 
 	p := f.GetPair()
 	vOp := reflect.ValueOf(p)
-	col := vOp.FieldByName("Column").String()
+	name := vOp.FieldByName("Column").String()
+	col, err := bs.column(name)
+	if err != nil {
+		return qry, args, offset, err
+	}
 	val := vOp.FieldByName("Value").Interface().([]Value)
 
-	args = make([]any, 0, 10)
-	qry = col + " " + operator + " ("
+	// Unset data fields are left out of the list.
+	// A NULL member is tested with IS NULL since IN never matches it.
+	args = make([]any, 0, len(val))
 	for _, pr := range val {
-		v, err = getFilterValue(srcData, pr)
+		v, err = bs.value(srcData, pr)
 		if err != nil {
 			return qry, args, offset, err
 		}
 		if v == nil {
-			return qry, args, offset, err
+			continue
 		}
 		switch v.(type) {
 		case Null:
-			return qry, args, offset, err
+			hasNull = true
+			continue
+		case ColumnRef:
+			return qry, args, offset, ErrColumnRefNotSupported
 		}
-		offset++
-		ph = strings.TrimSpace(ph)
-		qry += cma + ph
-		if inSeq && ph != "?" {
-			qry += strconv.Itoa(offset)
+		if v, err = bs.arg(name, v); err != nil {
+			return qry, args, offset, err
 		}
 		args = append(args, v)
-		cma = ","
 	}
-	qry += ")"
 
+	in := operator == "IN"
+	if len(args) == 0 {
+		switch {
+		case hasNull && in:
+			qry = col + " IS NULL"
+		case hasNull:
+			qry = col + " IS NOT NULL"
+		case in:
+			qry = "1=0"
+		case !bs.dropEmptyNotIn:
+			qry = "1=1"
+		}
+		return qry, args, offset, nil
+	}
+
+	qry, args, offset, err = buildList(col, operator, args, bs, offset)
+	if err != nil || !hasNull {
+		return qry, args, offset, err
+	}
+	if in {
+		return "(" + qry + " OR " + col + " IS NULL)", args, offset, nil
+	}
+	return "(" + qry + " AND " + col + " IS NOT NULL)", args, offset, nil
+}
+
+// buildList renders the membership of col in a list of values with the IN strategy of the dialect
+func buildList(col, operator string, args []any, bs *buildState, offset int) (string, []any, int, error) {
+	var qry, ph string
+
+	switch bs.inStrategy() {
+	case InArray:
+		ph, offset = bs.param(col, offset)
+		if operator == "IN" {
+			qry = col + " = ANY(" + ph + ")"
+		} else {
+			qry = col + " <> ALL(" + ph + ")"
+		}
+		return qry, []any{bs.dialect.arrayArg(args)}, offset, nil
+	case InTable:
+		arg, err := bs.dialect.tableArg(args)
+		if err != nil {
+			return qry, nil, offset, err
+		}
+		ph, offset = bs.param(col, offset)
+		expr, err := bs.dialect.tableExpr(ph)
+		if err != nil {
+			return qry, nil, offset, err
+		}
+		qry = col + " " + operator + " (" + expr + ")"
+		return qry, []any{arg}, offset, nil
+	case InChunk:
+		size := bs.dialect.chunkSize()
+		if len(args) <= size {
+			break
+		}
+		parts := make([]string, 0, len(args)/size+1)
+		for i := 0; i < len(args); i += size {
+			ph, offset = bs.params(col, min(size, len(args)-i), offset)
+			parts = append(parts, col+" "+operator+" ("+ph+")")
+		}
+		join := " OR "
+		if operator != "IN" {
+			join = " AND "
+		}
+		return "(" + strings.Join(parts, join) + ")", args, offset, nil
+	}
+
+	ph, offset = bs.params(col, len(args), offset)
+	qry = col + " " + operator + " (" + ph + ")"
 	return qry, args, offset, nil
 }
 
-func buildRangePair(f Filterer, srcData any, ph string, inSeq bool, offset int) (string, []any, int, error) {
+// buildRangePair renders BETWEEN or NOT BETWEEN. A bound that is unset in the Data or NULL leaves the range open
+// on that side with the semantics of Range, so NOT BETWEEN with a lower bound only renders col < lower.
+func buildRangePair(f Filterer, srcData any, operator string, bs *buildState, offset int) (string, []any, int, error) {
 	var (
-		qry, cma string
-		v        any
-		args     []any
-		err      error
+		qry, ph string
+		args    []any
+		err     error
 	)
 
 	p := f.GetPair()
 	vOp := reflect.ValueOf(p)
-	col := vOp.FieldByName("Column").String()
+	name := vOp.FieldByName("Column").String()
+	col, err := bs.column(name)
+	if err != nil {
+		return qry, args, offset, err
+	}
 	val := vOp.FieldByName("Value").Interface().([]Value)
 
-	args = make([]any, 0, 10)
+	args = make([]any, 0, 2)
 	if len(val) != 2 {
 		return qry, args, offset, ErrPairTypeMustBeTwo
 	}
 
-	qry = col + " BETWEEN "
+	bounds := make([]any, 0, 2)
 	for _, pr := range val {
-		v, err = getFilterValue(srcData, pr)
+		v, err := rangeBound(bs, srcData, &pr)
 		if err != nil {
 			return qry, args, offset, err
 		}
-		if v == nil {
-			return qry, args, offset, err
+		bounds = append(bounds, v)
+	}
+	if bounds[0] == nil || bounds[1] == nil {
+		r := Range{Column: name}
+		switch {
+		case operator == "BETWEEN":
+			r.From, r.To = openBound(val[0], bounds[0]), openBound(val[1], bounds[1])
+		case bounds[0] != nil:
+			r.To, r.ToExclusive = &val[0], true
+		case bounds[1] != nil:
+			r.From, r.FromExclusive = &val[1], true
 		}
-		switch v.(type) {
-		case Null:
+		str, rv, offset, err := r.build(bs, srcData, offset)
+		if err != nil || rv == nil {
+			return str, args, offset, err
+		}
+		return str, rv.([]any), offset, nil
+	}
+
+	qry = col + " " + operator + " "
+	cma := ""
+	for _, v := range bounds {
+		if v, err = bs.arg(name, v); err != nil {
 			return qry, args, offset, err
 		}
-		offset++
-		ph = strings.TrimSpace(ph)
+		ph, offset = bs.param(col, offset)
 		qry += cma + " " + ph
-		if inSeq && ph != "?" {
-			qry += strconv.Itoa(offset)
-		}
 		args = append(args, v)
 		cma = " AND "
 	}
 
 	return qry, args, offset, nil
 }
+
+// openBound gets the bound of a Range, nil when it is open
+func openBound(p Value, v any) *Value {
+	if v == nil {
+		return nil
+	}
+	return &p
+}
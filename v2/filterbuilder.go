@@ -2,6 +2,8 @@ package filterbuilder
 
 import (
 	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -23,6 +25,34 @@ var (
 	ErrPairTypeMustHaveMoreThanTwo error = errors.New("pair type must have more than two")
 	ErrPairTypeMustBeTwo           error = errors.New("pair type must be two")
 	ErrSourceIsNil                 error = errors.New("source is nil")
+	ErrTooManyParameters           error = errors.New("too many parameters for dialect")
+	ErrNoTableExpr                 error = errors.New("dialect has no table expression")
+	ErrInvalidRelativeTime         error = errors.New("invalid relative time expression")
+	ErrColumnNotAllowed            error = errors.New("column not allowed")
+	ErrColumnRefNotSupported       error = errors.New("column reference not supported")
+	ErrFiltererNotSerializable     error = errors.New("filterer not serializable")
+	ErrRelationNotFound            error = errors.New("relation not found")
+	ErrExprArgCount                error = errors.New("expression markers and arguments do not match")
+	ErrTupleSize                   error = errors.New("tuple size does not match the columns")
+	ErrSeekSize                    error = errors.New("seek values do not match the keys")
+	ErrSeekNullKey                 error = errors.New("seek key value is null")
+	ErrInvalidCursor               error = errors.New("invalid cursor")
+	ErrCursorValueType             error = errors.New("cursor value type not supported")
+	ErrCursorSecret                error = errors.New("cursor secret is empty")
+	ErrInvalidSort                 error = errors.New("invalid sort")
+	ErrOffsetNotSupported          error = errors.New("offset not supported by the dialect")
+	ErrNoAssignments               error = errors.New("no assignments set")
+	ErrRequiredColumn              error = errors.New("required column not constrained")
+	ErrNamedArgMismatch            error = errors.New("named parameters do not match the arguments")
+	ErrLiteralType                 error = errors.New("value cannot be rendered as a literal")
+	ErrTooFewArguments             error = errors.New("too few arguments for the placeholders")
+	ErrRebindOrder                 error = errors.New("numbered placeholders are not in order")
+	ErrValueType                   error = errors.New("value does not match the column type")
+	ErrEnumValue                   error = errors.New("value not allowed for the column")
+	ErrSchemaRequired              error = errors.New("a schema is required to reference columns")
+	ErrInvalidIdentifier           error = errors.New("column name is not a valid identifier")
+	ErrExprEscapedMarker           error = errors.New("escaped ? marker cannot be told from the placeholders of the dialect")
+	ErrWeldSetOperator             error = errors.New("statement with a set operator needs a filter marker")
 )
 
 type (
@@ -79,6 +109,92 @@ func AllowNoFilters(value bool) FilterOption {
 	}
 }
 
+// UseDialect sets the dialect, its placeholder and sequence
+func UseDialect(d Dialect) FilterOption {
+	return func(f *Filter) {
+		f.Dialect = &d
+		f.Placeholder = d.Placeholder
+		f.InSequence = d.InSequence
+	}
+}
+
+// DropEmptyNotIn drops a NOT IN with an empty list instead of rendering an always true predicate
+func DropEmptyNotIn(value bool) FilterOption {
+	return func(f *Filter) {
+		f.DropEmptyNotIn = value
+	}
+}
+
+// Clock sets the clock that resolves relative time expressions
+func Clock(now func() time.Time) FilterOption {
+	return func(f *Filter) {
+		f.Clock = now
+	}
+}
+
+// Location sets the time zone of resolved relative time expressions
+func Location(loc *time.Location) FilterOption {
+	return func(f *Filter) {
+		f.Location = loc
+	}
+}
+
+// UseSchema sets the registry of the columns that can be referenced
+func UseSchema(s Schema) FilterOption {
+	return func(f *Filter) {
+		f.Schema = s
+	}
+}
+
+// PushDownNot applies negations to the negated filters
+func PushDownNot(value bool) FilterOption {
+	return func(f *Filter) {
+		f.PushDownNot = value
+	}
+}
+
+// UseRelations sets the registry of the relations that Exists filters can reference
+func UseRelations(r Relations) FilterOption {
+	return func(f *Filter) {
+		f.Relations = r
+	}
+}
+
+// UseAggregates sets the registry of the aggregate expressions that the Having filter can reference
+func UseAggregates(s Schema) FilterOption {
+	return func(f *Filter) {
+		f.Aggregates = s
+	}
+}
+
+// RequireColumns sets the columns that the filter of an UPDATE or DELETE statement must constrain
+func RequireColumns(cols ...string) FilterOption {
+	return func(f *Filter) {
+		f.Required = cols
+	}
+}
+
+// DedupArgs sets identical arguments to share one placeholder when the placeholders are numbered
+func DedupArgs(value bool) FilterOption {
+	return func(f *Filter) {
+		f.DedupArgs = value
+	}
+}
+
+// RebindBase sets the dialect of the SQL string given to Weld, so its placeholders are rebound to those of the filter
+func RebindBase(d Dialect) FilterOption {
+	return func(f *Filter) {
+		f.BaseDialect = &d
+	}
+}
+
+// ConvertArgs sets the converters applied to every argument of the filter
+func ConvertArgs(cs ...ArgConverter) FilterOption {
+	return func(f *Filter) {
+		f.Converters = cs
+	}
+}
+
 // NewPairs simplify initialization of Filterer
 func NewPairs[T Filterer](pairs ...T) []T {
 	return pairs
@@ -96,14 +212,25 @@ func (fb *Filter) BuildFunc(poff int, pchar string, pseq bool) ([]string, []any)
 // Build the filter query
 func (fb *Filter) Build() ([]string, []any, error) {
 
-	var (
-		sql  []string
-		args []any
-		err  error
-		rv   any
-		str  string
-	)
+	start := fb.Offset
+
+	fb.defaultPlaceholder()
+	if fb.empty() && !fb.AllowNoFilters {
+		return []string{}, []any{}, ErrNoFilterSet
+	}
+
+	bs := fb.newBuildState()
+	sql, args, err := fb.build(bs)
+	if err != nil {
+		return sql, args, err
+	}
+
+	sql, args = fb.dedup(bs, sql, args, start)
+	return sql, args, fb.checkParams(start + len(args))
+}
 
+// defaultPlaceholder sets the parameter place holder when it is not set
+func (fb *Filter) defaultPlaceholder() {
 	if fb.Placeholder == "" {
 		if fb.InSequence {
 			fb.Placeholder = "@p"
@@ -111,24 +238,54 @@ func (fb *Filter) Build() ([]string, []any, error) {
 			fb.Placeholder = "?"
 		}
 	}
+}
 
-	sql = make([]string, 0, 10)
-	args = make([]any, 0, 10)
-
-	if len(fb.In) == 0 &&
+// empty tells if no filter is set
+func (fb *Filter) empty() bool {
+	return len(fb.In) == 0 &&
 		len(fb.NotIn) == 0 &&
 		len(fb.Ne) == 0 &&
 		len(fb.Eq) == 0 &&
 		len(fb.Or) == 0 &&
 		len(fb.Lk) == 0 &&
 		len(fb.Between) == 0 &&
+		len(fb.NotBetween) == 0 &&
+		len(fb.Range) == 0 &&
+		len(fb.Not) == 0 &&
+		len(fb.TupleIn) == 0 &&
+		len(fb.Seek) == 0 &&
+		len(fb.Exists) == 0 &&
+		len(fb.NotExists) == 0 &&
+		len(fb.InQuery) == 0 &&
+		len(fb.NotInQuery) == 0 &&
+		len(fb.Expr) == 0 &&
 		len(fb.Lt) == 0 &&
 		len(fb.Lte) == 0 &&
 		len(fb.Gt) == 0 &&
-		len(fb.Gte) == 0 &&
-		!fb.AllowNoFilters {
-		return sql, args, ErrNoFilterSet
+		len(fb.Gte) == 0
+}
+
+// checkParams checks the number of parameters of a statement against the limit of the dialect
+func (fb *Filter) checkParams(n int) error {
+	if fb.Dialect != nil && fb.Dialect.MaxParams > 0 && n > fb.Dialect.MaxParams {
+		return ErrTooManyParameters
 	}
+	return nil
+}
+
+// build the filter query with the settings in bs, numbering parameters from the Offset of the filter
+func (fb *Filter) build(bs *buildState) ([]string, []any, error) {
+
+	var (
+		sql  []string
+		args []any
+		err  error
+		rv   any
+		str  string
+	)
+
+	sql = make([]string, 0, 10)
+	args = make([]any, 0, 10)
 
 	// Check if Ors pair is two or more
 	for _, ors := range fb.Or {
@@ -139,108 +296,117 @@ func (fb *Filter) Build() ([]string, []any, error) {
 
 	// Get Equality filters
 	for _, sv := range fb.Eq {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
 		if rv != nil {
 			args = append(args, rv)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
 
 	for _, sv := range fb.Lt {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
 		if rv != nil {
 			args = append(args, rv)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
 
 	for _, sv := range fb.Lte {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
 		if rv != nil {
 			args = append(args, rv)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
 
 	for _, sv := range fb.Gt {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
 		if rv != nil {
 			args = append(args, rv)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
 
 	for _, sv := range fb.Gte {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
 		if rv != nil {
 			args = append(args, rv)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
 
 	// Get Or filters
 	// An Or is an array of Filterer
 	// A group of Or is joined by an AND clause
 	for _, ors := range fb.Or {
-		str := ""
-		orsarr := make([]string, 0)
-		for _, orx := range ors.Pair {
-			str, rv, fb.Offset, err = orx.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
-			if err != nil {
-				return sql, args, err
-			}
-			if isSliceType(rv) {
-				args = append(args, rv.([]any)...)
-			} else {
-				args = append(args, rv)
-			}
-			orsarr = append(orsarr, str)
+		str, rv, fb.Offset, err = buildFilterer(ors, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		if isSliceType(rv) {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
 		}
-		sql = append(sql, "("+strings.Join(orsarr, " OR ")+")")
 	}
 
 	// Get Non-Equality filters
 	for _, sv := range fb.Ne {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
 		if rv != nil {
 			args = append(args, rv)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
 
 	// Get Like filters
 	for _, sv := range fb.Lk {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
 		if rv != nil {
 			args = append(args, rv)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
 
 	// Get In filters
 	for _, sv := range fb.In {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
@@ -248,12 +414,14 @@ func (fb *Filter) Build() ([]string, []any, error) {
 		if len(rvs) > 0 {
 			args = append(args, rv.([]any)...)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
 
 	// Get Not In filters
 	for _, sv := range fb.NotIn {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
@@ -261,12 +429,105 @@ func (fb *Filter) Build() ([]string, []any, error) {
 		if len(rvs) > 0 {
 			args = append(args, rv.([]any)...)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
 
 	// Get Between filters
 	for _, sv := range fb.Between {
-		str, rv, fb.Offset, err = sv.Build(fb.Data, fb.Placeholder, fb.InSequence, fb.Offset)
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		rvs := rv.([]any)
+		if len(rvs) > 0 {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
+	// Get Not Between filters
+	for _, sv := range fb.NotBetween {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		rvs := rv.([]any)
+		if len(rvs) > 0 {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
+	// Get Range filters
+	for _, sv := range fb.Range {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		rvs := rv.([]any)
+		if len(rvs) > 0 {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
+	// Get Not filters
+	for _, sv := range fb.Not {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		if isSliceType(rv) {
+			args = append(args, rv.([]any)...)
+		} else if rv != nil {
+			args = append(args, rv)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
+	// Get Tuple In filters
+	for _, sv := range fb.TupleIn {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		rvs := rv.([]any)
+		if len(rvs) > 0 {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
+	// Get Seek filters
+	for _, sv := range fb.Seek {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		rvs := rv.([]any)
+		if len(rvs) > 0 {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
+	// Get Exists filters
+	for _, sv := range fb.Exists {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
 		if err != nil {
 			return sql, args, err
 		}
@@ -274,13 +535,121 @@ func (fb *Filter) Build() ([]string, []any, error) {
 		if len(rvs) > 0 {
 			args = append(args, rv.([]any)...)
 		}
-		sql = append(sql, str)
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
+	// Get Not Exists filters
+	for _, sv := range fb.NotExists {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		rvs := rv.([]any)
+		if len(rvs) > 0 {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
+	// Get In Query filters
+	for _, sv := range fb.InQuery {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		rvs := rv.([]any)
+		if len(rvs) > 0 {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
+	// Get Not In Query filters
+	for _, sv := range fb.NotInQuery {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		rvs := rv.([]any)
+		if len(rvs) > 0 {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
 	}
+
+	// Get Expr filters
+	for _, sv := range fb.Expr {
+		str, rv, fb.Offset, err = buildFilterer(sv, bs, fb.Data, fb.Offset)
+		if err != nil {
+			return sql, args, err
+		}
+		rvs := rv.([]any)
+		if len(rvs) > 0 {
+			args = append(args, rv.([]any)...)
+		}
+		if str != "" {
+			sql = append(sql, str)
+		}
+	}
+
 	return sql, args, nil
 }
 
+func (fb *Filter) newBuildState() *buildState {
+	bs := newBuildState(fb.Placeholder, fb.InSequence)
+	bs.dialect = fb.Dialect
+	bs.dropEmptyNotIn = fb.DropEmptyNotIn
+	bs.clock = fb.Clock
+	bs.loc = fb.Location
+	bs.schema = fb.Schema
+	bs.pushDownNot = fb.PushDownNot
+	bs.relations = fb.Relations
+	bs.converters = fb.Converters
+	return bs
+}
+
+// BuildSort builds the terms of the ORDER BY clause from the Sort of the filter
+func (fb *Filter) BuildSort() ([]string, error) {
+	return buildSort(fb.Sort, fb.newBuildState())
+}
+
 // ValueFor gets the value of the filter instance by column lookup
+// Values are coerced to the type of the column in the Schema.
 func (fb *Filter) ValueFor(col string) (any, error) {
+	v, err := fb.valueFor(col)
+	if err != nil {
+		return v, err
+	}
+
+	var c Column
+	if fb.Schema != nil {
+		_, c, _ = fb.Schema.lookup(col)
+	}
+	bs := fb.newBuildState()
+	vs, ok := v.([]any)
+	if !ok {
+		return bs.coerce(c, v)
+	}
+	out := make([]any, 0, len(vs))
+	for _, e := range vs {
+		cv, err := bs.coerce(c, e)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, cv)
+	}
+	return out, nil
+}
+
+func (fb *Filter) valueFor(col string) (any, error) {
 	for _, v := range fb.Eq {
 		if strings.EqualFold(v.Column, col) {
 			return fb.Value(v.Value)
@@ -297,9 +666,11 @@ func (fb *Filter) ValueFor(col string) (any, error) {
 			case Eq, Ne, Lk:
 				val := vOp.FieldByName("Value").Interface().(Value)
 				return fb.Value(val)
-			case Ni, In, Bw:
+			case Ni, In, Bw, Nb:
 				val := vOp.FieldByName("Value").Interface().([]Value)
 				return fb.Values(val)
+			case Range:
+				return fb.Values(v.(Range).Values())
 			}
 		}
 	}
@@ -328,6 +699,16 @@ func (fb *Filter) ValueFor(col string) (any, error) {
 			return fb.Values(v.Value)
 		}
 	}
+	for _, v := range fb.NotBetween {
+		if strings.EqualFold(v.Column, col) {
+			return fb.Values(v.Value)
+		}
+	}
+	for _, v := range fb.Range {
+		if strings.EqualFold(v.Column, col) {
+			return fb.Values(v.Values())
+		}
+	}
 	return nil, ErrColumnNotFound
 }
 
@@ -347,13 +728,14 @@ func ValueFor[T FilterConstraints](fb *Filter, col string) (T, error) {
 		vx = t.Elem().Interface()
 	} else if t.Kind() == reflect.Slice {
 		tType := reflect.TypeOf((*T)(nil)).Elem()
-		toS := tType.Elem()
+		if tType.Kind() != reflect.Slice {
+			return *new(T), ErrDataAssertionMismatch
+		}
 		vy := reflect.MakeSlice(tType, t.Len(), t.Len())
 		for i := range t.Len() {
-			item := t.Index(i).Interface()
-			val := reflect.ValueOf(item)
-			if !val.Type().AssignableTo(toS) {
-				panic(fmt.Sprintf("element %d is not assignable to %s", i, toS))
+			val, ok := convertTo(t.Index(i).Interface(), tType.Elem())
+			if !ok {
+				return *new(T), ErrDataAssertionMismatch
 			}
 			vy.Index(i).Set(val)
 		}
@@ -365,40 +747,93 @@ func ValueFor[T FilterConstraints](fb *Filter, col string) (T, error) {
 			return *new(T), ErrSourceIsNil
 		}
 	}
-	val, ok := vx.(T)
+	val, ok := convertValue[T](vx)
 	if !ok {
 		return *new(T), ErrDataAssertionMismatch
 	}
 	return val, err
 }
 
-// Weld joins an existing SQL string and its arguments with the results from the Build function
+// Weld joins an existing SQL string and its arguments with the results from the Build function.
+//
+// The filter replaces the first /*filter*/ marker in the SQL string, or 1=1 when it has no conditions.
+// Without a marker, the filter is joined with AND to the top level WHERE clause, or added as a WHERE clause
+// before any GROUP BY, HAVING, WINDOW, ORDER BY, LIMIT, OFFSET, FETCH, FOR UPDATE or RETURNING clause.
+// A statement with a top level UNION, INTERSECT, EXCEPT or MINUS needs a marker, since the filter would
+// otherwise apply to one of its queries only, and fails with ErrWeldSetOperator.
+// Keywords in string literals, comments and subqueries are ignored.
+// Parameters that are not numbered are inserted among args at the position of the filter.
+// When a BaseDialect is set, the placeholders of the SQL string are first rebound to those of the filter,
+// so paramoffset should be the number of args.
 func (fb *Filter) Weld(sql string, args []any, paramoffset int) (string, []any, error) {
 	fb.Offset = paramoffset
 	fexp, fargs, err := fb.Build()
 	if err != nil {
 		return sql, args, err
 	}
-	if len(fexp) > 0 {
-		// remove trailing space and semi-colon
-		sql = strings.TrimSpace(sql)
-		sql = strings.TrimRight(sql, `;`)
-		sql += " WHERE " + strings.Join(fexp, " AND ")
+
+	// remove trailing space and semi-colon
+	src := strings.TrimRight(strings.TrimSpace(sql), `;`)
+	if fb.BaseDialect != nil {
+		to := Dialect{Placeholder: fb.Placeholder, InSequence: fb.InSequence}
+		if src, err = Rebind(src, *fb.BaseDialect, to, 0); err != nil {
+			return sql, args, err
+		}
+		sql = src
 	}
-	if len(fargs) > 0 {
-		args = append(args, fargs...)
+	wp := findWeldPoint(src, fb.Dialect)
+	cond := strings.Join(fexp, " AND ")
+
+	var at int
+	switch {
+	case wp.marker >= 0:
+		switch {
+		case len(fexp) == 0:
+			cond = "1=1"
+		case len(fexp) > 1:
+			cond = "(" + cond + ")"
+		}
+		at = wp.marker
+		sql = src[:at] + cond + src[at+len(weldMarker):]
+	case len(fexp) == 0:
+		return sql, args, nil
+	case wp.setOp >= 0:
+		return sql, args, ErrWeldSetOperator
+	case wp.where >= 0:
+		at = wp.end
+		where := strings.TrimSpace(src[wp.cond:at])
+		sql = src[:wp.cond] + " (" + where + lineEnd(where, fb.Dialect) + ") AND " + cond
+	default:
+		at = wp.end
+		sql = strings.TrimSpace(src[:at])
+		sql += lineEnd(sql, fb.Dialect) + " WHERE " + cond
 	}
-	return sql, args, nil
+	if wp.marker < 0 && at < len(src) {
+		sql += " " + src[at:]
+	}
+
+	if len(fargs) == 0 {
+		return sql, args, nil
+	}
+	n := len(args)
+	if !fb.InSequence || fb.Placeholder == "?" {
+		n = min(countMarkers(src[:at], fb.Placeholder, fb.Dialect), n)
+	}
+	welded := make([]any, 0, len(args)+len(fargs))
+	welded = append(welded, args[:n]...)
+	welded = append(welded, fargs...)
+	welded = append(welded, args[n:]...)
+	return sql, welded, nil
 }
 
 // Value gets the actual value of the struct field or the raw value that has been set
 func (fb *Filter) Value(p Value) (any, error) {
-	return getFilterValue(fb.Data, p)
+	return fb.newBuildState().value(fb.Data, p)
 }
 
 // Values gets the actual values of the struct field or the raw value that has been set
 func (fb *Filter) Values(p []Value) ([]any, error) {
-	return getFilterValues(fb.Data, p)
+	return fb.newBuildState().values(fb.Data, p)
 }
 
 // Value gets the actual value of the struct field or the raw value that has been set
@@ -409,13 +844,13 @@ func getFilterValue(data any, p Value) (any, error) {
 		}
 		rv := reflect.ValueOf(p.Src)
 		if rv.Kind() != reflect.Ptr {
-			return p.Src, nil
+			return resolveValuer(p.Src)
 		}
 		vv := rv.Elem()
 		if !vv.IsValid() {
 			return Null(true), nil
 		}
-		return p.Src, nil
+		return resolveValuer(p.Src)
 	}
 	if data == nil {
 		return nil, ErrDataNotSet
@@ -447,24 +882,27 @@ func getFilterValue(data any, p Value) (any, error) {
 			return nil, ErrSourceIsNil
 		}
 	}
-	return vx, nil
+	return resolveValuer(vx)
 }
 
-func getFilterValues(data any, p []Value) ([]any, error) {
-	var (
-		err  error
-		args []any
-		v    any
-	)
-	args = make([]any, 0)
-	for _, mv := range p {
-		v, err = getFilterValue(data, mv)
+// resolveValuer resolves a driver.Valuer, such as sql.NullString or sql.Null[T], to the value it binds.
+// A valuer that binds nil, such as a sql.NullInt64 that is not Valid, resolves to Null.
+// Decimals are kept as they are.
+func resolveValuer(v any) (any, error) {
+	switch t := v.(type) {
+	case ssd.Decimal, *ssd.Decimal:
+		return v, nil
+	case driver.Valuer:
+		dv, err := t.Value()
 		if err != nil {
-			return args, err
+			return nil, err
+		}
+		if dv == nil {
+			return Null(true), nil
 		}
-		args = append(args, v)
+		return dv, nil
 	}
-	return args, nil
+	return v, nil
 }
 
 // Valid checks if any filters were defined
@@ -475,7 +913,107 @@ func (fb *Filter) Valid() bool {
 		len(fb.Lk) > 0 ||
 		len(fb.In) > 0 ||
 		len(fb.NotIn) > 0 ||
-		len(fb.Between) > 0
+		len(fb.Between) > 0 ||
+		len(fb.NotBetween) > 0 ||
+		len(fb.Range) > 0 ||
+		len(fb.Not) > 0 ||
+		len(fb.TupleIn) > 0 ||
+		len(fb.Seek) > 0 ||
+		len(fb.Exists) > 0 ||
+		len(fb.NotExists) > 0 ||
+		len(fb.InQuery) > 0 ||
+		len(fb.NotInQuery) > 0 ||
+		len(fb.Expr) > 0
+}
+
+// Exprs lists every trusted SQL expression in the filter,
+// including the ones nested in Group, Or, Not and subqueries, so they can be reviewed
+func (fb *Filter) Exprs() []Expr {
+	exprs := []Expr{}
+	fb.walk(func(f Filterer) {
+		if e, ok := f.(Expr); ok {
+			exprs = append(exprs, e)
+		}
+	})
+	return exprs
+}
+
+// terms lists the filters of every kind in the filter, in the order they are built
+func (fb *Filter) terms() []Filterer {
+	terms := make([]Filterer, 0, 10)
+	terms = appendTerms(terms, fb.Eq)
+	terms = appendTerms(terms, fb.Lt)
+	terms = appendTerms(terms, fb.Lte)
+	terms = appendTerms(terms, fb.Gt)
+	terms = appendTerms(terms, fb.Gte)
+	terms = appendTerms(terms, fb.Or)
+	terms = appendTerms(terms, fb.Ne)
+	terms = appendTerms(terms, fb.Lk)
+	terms = appendTerms(terms, fb.In)
+	terms = appendTerms(terms, fb.NotIn)
+	terms = appendTerms(terms, fb.Between)
+	terms = appendTerms(terms, fb.NotBetween)
+	terms = appendTerms(terms, fb.Range)
+	terms = appendTerms(terms, fb.Not)
+	terms = appendTerms(terms, fb.TupleIn)
+	terms = appendTerms(terms, fb.Seek)
+	terms = appendTerms(terms, fb.Exists)
+	terms = appendTerms(terms, fb.NotExists)
+	terms = appendTerms(terms, fb.InQuery)
+	terms = appendTerms(terms, fb.NotInQuery)
+	terms = appendTerms(terms, fb.Expr)
+	return terms
+}
+
+func appendTerms[T Filterer](terms []Filterer, fs []T) []Filterer {
+	for _, f := range fs {
+		terms = append(terms, f)
+	}
+	return terms
+}
+
+// walk calls fn for every filter in the filter, descending into Group, Or, Not, subqueries and the Having filter
+func (fb *Filter) walk(fn func(Filterer)) {
+	for _, f := range fb.terms() {
+		walkFilterer(f, fn)
+	}
+	if fb.Having != nil {
+		fb.Having.walk(fn)
+	}
+}
+
+func walkFilterer(f Filterer, fn func(Filterer)) {
+	fn(f)
+	switch t := f.(type) {
+	case Group:
+		for _, a := range t.And {
+			walkFilterer(a, fn)
+		}
+	case Or:
+		for _, p := range t.Pair {
+			walkFilterer(p, fn)
+		}
+	case Not:
+		if t.Filter != nil {
+			walkFilterer(t.Filter, fn)
+		}
+	case Exists:
+		if t.Where != nil {
+			t.Where.walk(fn)
+		}
+	case NotExists:
+		if t.Where != nil {
+			t.Where.walk(fn)
+		}
+	case InQuery:
+		if t.Where != nil {
+			t.Where.walk(fn)
+		}
+	case NotInQuery:
+		if t.Where != nil {
+			t.Where.walk(fn)
+		}
+	}
 }
 
 // MakeKey creates a unique key out of the filters created
@@ -487,7 +1025,7 @@ func (fb *Filter) MakeKey() string {
 		}
 		sb.WriteString(sanitizeColumnForHash(v.Column))
 		sb.WriteString("=")
-		val, _ := fb.Value(v.Value)
+		val, _ := fb.keyValue(v.Value)
 		sb.WriteString("\"" + sanitizeValueForHash(anyToString(val)) + "\"")
 	}
 	for _, vfs := range fb.Or {
@@ -495,24 +1033,7 @@ func (fb *Filter) MakeKey() string {
 			if sb.Len() > 0 {
 				sb.WriteString("-")
 			}
-			vOp := reflect.ValueOf(v)
-			col := vOp.FieldByName("Column").String()
-			switch v.(type) {
-			case Eq, Ne, Lk:
-				val := vOp.FieldByName("Value").Interface().(Value)
-				sb.WriteString(sanitizeColumnForHash(col))
-				sb.WriteString("=")
-				valf, _ := fb.Value(val)
-				sb.WriteString("\"" + sanitizeValueForHash(anyToString(valf)) + "\"")
-			case Ni, In, Bw:
-				val := vOp.FieldByName("Value").Interface().([]Value)
-				sb.WriteString(sanitizeColumnForHash(col))
-				sb.WriteString("=")
-				valf, _ := fb.Values(val)
-				for _, vf := range valf {
-					sb.WriteString("\"" + sanitizeValueForHash(anyToString(vf)) + "\"")
-				}
-			}
+			sb.WriteString(fb.filtererKey(v))
 		}
 	}
 	for _, v := range fb.Ne {
@@ -521,7 +1042,7 @@ func (fb *Filter) MakeKey() string {
 		}
 		sb.WriteString(sanitizeColumnForHash(v.Column))
 		sb.WriteString("=!")
-		val, _ := fb.Value(v.Value)
+		val, _ := fb.keyValue(v.Value)
 		sb.WriteString("\"" + sanitizeValueForHash(anyToString(val)) + "\"")
 	}
 	for _, v := range fb.Lk {
@@ -530,7 +1051,7 @@ func (fb *Filter) MakeKey() string {
 		}
 		sb.WriteString(sanitizeColumnForHash(v.Column))
 		sb.WriteString("=%\"")
-		val, _ := fb.Value(v.Value)
+		val, _ := fb.keyValue(v.Value)
 		sb.WriteString(sanitizeValueForHash(anyToString(val)))
 		sb.WriteString("\"")
 	}
@@ -540,22 +1061,31 @@ func (fb *Filter) MakeKey() string {
 		}
 		sb.WriteString(sanitizeColumnForHash(v.Column))
 		sb.WriteString("=|\"")
-		vals, _ := getFilterValues(fb.Data, v.Value)
-		for i, val := range vals {
-			sb.WriteString(sanitizeValueForHash(anyToString(val)))
-			if i < len(vals)-1 {
-				sb.WriteString(",")
-			}
-		}
+		vals, _ := fb.keyValues(v.Value)
+		sb.WriteString(keyList(vals))
 		sb.WriteString("\"")
 	}
 	for _, v := range fb.NotIn {
+		vals, _ := fb.keyValues(v.Value)
+		list := keyList(vals)
+		if list == "" && fb.DropEmptyNotIn {
+			continue
+		}
 		if sb.Len() > 0 {
 			sb.WriteString("-")
 		}
 		sb.WriteString(sanitizeColumnForHash(v.Column))
 		sb.WriteString("=!|\"")
-		vals, _ := getFilterValues(fb.Data, v.Value)
+		sb.WriteString(list)
+		sb.WriteString("\"")
+	}
+	for _, v := range fb.Between {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(sanitizeColumnForHash(v.Column))
+		sb.WriteString("=+\"")
+		vals, _ := fb.keyValues(v.Value)
 		for i, val := range vals {
 			sb.WriteString(sanitizeValueForHash(anyToString(val)))
 			if i < len(vals)-1 {
@@ -564,13 +1094,13 @@ func (fb *Filter) MakeKey() string {
 		}
 		sb.WriteString("\"")
 	}
-	for _, v := range fb.Between {
+	for _, v := range fb.NotBetween {
 		if sb.Len() > 0 {
 			sb.WriteString("-")
 		}
 		sb.WriteString(sanitizeColumnForHash(v.Column))
-		sb.WriteString("=+\"")
-		vals, _ := getFilterValues(fb.Data, v.Value)
+		sb.WriteString("=!+\"")
+		vals, _ := fb.keyValues(v.Value)
 		for i, val := range vals {
 			sb.WriteString(sanitizeValueForHash(anyToString(val)))
 			if i < len(vals)-1 {
@@ -579,9 +1109,261 @@ func (fb *Filter) MakeKey() string {
 		}
 		sb.WriteString("\"")
 	}
+	for _, v := range fb.Range {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(sanitizeColumnForHash(v.Column))
+		sb.WriteString("=~")
+		sb.WriteString(fb.rangeKey(v))
+	}
+	for _, v := range fb.Not {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(fb.filtererKey(v))
+	}
+	for _, v := range fb.TupleIn {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(fb.filtererKey(v))
+	}
+	for _, v := range fb.Seek {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(fb.filtererKey(v))
+	}
+	for _, v := range fb.Exists {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(fb.filtererKey(v))
+	}
+	for _, v := range fb.NotExists {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(fb.filtererKey(v))
+	}
+	for _, v := range fb.InQuery {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(fb.filtererKey(v))
+	}
+	for _, v := range fb.NotInQuery {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(fb.filtererKey(v))
+	}
+	for _, v := range fb.Expr {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString(fb.filtererKey(v))
+	}
+	if fb.Having != nil {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString("~having(" + fb.Having.MakeKey() + ")")
+	}
+	if len(fb.Sort) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("-")
+		}
+		sb.WriteString("~sort(")
+		for i, s := range fb.Sort {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			if s.Desc {
+				sb.WriteString("-")
+			}
+			sb.WriteString(sanitizeColumnForHash(s.Column))
+			if s.Nulls != NullsDefault {
+				sb.WriteString(":" + string(s.Nulls))
+			}
+		}
+		sb.WriteString(")")
+	}
 	return sb.String()
 }
 
+// filtererKey creates the key of a single Filterer
+func (fb *Filter) filtererKey(f Filterer) string {
+	var (
+		parts []string
+		join  string
+	)
+	switch t := f.(type) {
+	case Eq, Ne, Lk, Lt, Lte, Gt, Gte:
+		vOp := reflect.ValueOf(t)
+		col := vOp.FieldByName("Column").String()
+		val, _ := fb.keyValue(vOp.FieldByName("Value").Interface().(Value))
+		return sanitizeColumnForHash(col) + keyOperator(f) + "\"" + sanitizeValueForHash(anyToString(val)) + "\""
+	case Ni, In, Bw, Nb:
+		vOp := reflect.ValueOf(t)
+		col := vOp.FieldByName("Column").String()
+		vals, _ := fb.keyValues(vOp.FieldByName("Value").Interface().([]Value))
+		return sanitizeColumnForHash(col) + keyOperator(f) + "\"" + keyList(vals) + "\""
+	case Range:
+		return sanitizeColumnForHash(t.Column) + "=~" + fb.rangeKey(t)
+	case TupleIn:
+		cols := make([]string, 0, len(t.Columns))
+		for _, c := range t.Columns {
+			cols = append(cols, sanitizeColumnForHash(c))
+		}
+		rows := make([]string, 0, len(t.Value))
+		for _, r := range t.Value {
+			vals, _ := fb.keyValues(r)
+			rows = append(rows, "("+keyList(vals)+")")
+		}
+		return "(" + strings.Join(cols, ",") + ")=|\"" + strings.Join(rows, ",") + "\""
+	case Seek:
+		keys := make([]string, 0, len(t.Keys))
+		for _, k := range t.Keys {
+			col := sanitizeColumnForHash(k.Column)
+			if k.Desc {
+				col = "-" + col
+			}
+			keys = append(keys, col)
+		}
+		vals, _ := fb.keyValues(t.Value)
+		return "(" + strings.Join(keys, ",") + ")=>>\"" + keyList(vals) + "\""
+	case Exists:
+		return "?" + fb.existsKey(t)
+	case NotExists:
+		return "!?" + fb.existsKey(Exists(t))
+	case InQuery:
+		return sanitizeColumnForHash(t.Column) + "=|" + fb.inQueryKey(t)
+	case NotInQuery:
+		return sanitizeColumnForHash(t.Column) + "=!|" + fb.inQueryKey(InQuery(t))
+	case Expr:
+		return "sql(" + sanitizeValueForHash(t.SQL) + ")=\"" + keyList(t.Args) + "\""
+	case Not:
+		if t.Filter == nil {
+			return "!()"
+		}
+		return "!(" + fb.filtererKey(t.Filter) + ")"
+	case Group:
+		for _, a := range t.And {
+			parts = append(parts, fb.filtererKey(a))
+		}
+		join = "&"
+	case Or:
+		for _, p := range t.Pair {
+			parts = append(parts, fb.filtererKey(p))
+		}
+		join = "|"
+	default:
+		return ""
+	}
+	return "(" + strings.Join(parts, join) + ")"
+}
+
+// existsKey creates the key of the related table and the filter of an Exists
+func (fb *Filter) existsKey(e Exists) string {
+	src := e.Relation
+	if src == "" {
+		src = e.Table + ":" + e.On
+	}
+	if e.Where == nil {
+		return sanitizeColumnForHash(src) + "()"
+	}
+	inner := *e.Where
+	if inner.Data == nil {
+		inner.Data = fb.Data
+	}
+	return sanitizeColumnForHash(src) + "(" + inner.MakeKey() + ")"
+}
+
+// inQueryKey creates the key of the subquery of an InQuery
+func (fb *Filter) inQueryKey(f InQuery) string {
+	src := f.Relation
+	if src == "" {
+		src = f.Table
+	}
+	return fb.existsKey(Exists{
+		Relation: src + "." + f.Select,
+		Where:    f.Where,
+	})
+}
+
+// keyOperator gets the marker of the operator of a Filterer in a key
+func keyOperator(f Filterer) string {
+	switch f.(type) {
+	case Ne:
+		return "=!"
+	case Lk:
+		return "=%"
+	case Lt:
+		return "=<"
+	case Lte:
+		return "=<="
+	case Gt:
+		return "=>"
+	case Gte:
+		return "=>="
+	case In:
+		return "=|"
+	case Ni:
+		return "=!|"
+	case Bw:
+		return "=+"
+	case Nb:
+		return "=!+"
+	}
+	return "="
+}
+
+// rangeKey writes a range in interval notation, [a,b) for an inclusive lower and exclusive upper bound
+func (fb *Filter) rangeKey(r Range) string {
+	from, _ := fb.keyBound(r.From)
+	to, _ := fb.keyBound(r.To)
+	open, cls := "[", "]"
+	if r.FromExclusive {
+		open = "("
+	}
+	if r.ToExclusive {
+		cls = ")"
+	}
+	return open + "\"" + sanitizeValueForHash(anyToString(from)) + "\",\"" + sanitizeValueForHash(anyToString(to)) + "\"" + cls
+}
+
+// keyValue gets the value of p for the key. Relative time expressions are kept as their text,
+// so the key of a filter does not change with the clock.
+func (fb *Filter) keyValue(p Value) (any, error) {
+	if expr, ok := p.Src.(string); ok && p.Rel {
+		return "@" + expr, nil
+	}
+	return fb.Value(p)
+}
+
+// keyValues gets the values of p for the key
+func (fb *Filter) keyValues(p []Value) ([]any, error) {
+	vals := make([]any, 0, len(p))
+	for _, mv := range p {
+		v, err := fb.keyValue(mv)
+		if err != nil {
+			return vals, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+// keyBound gets the bound of a range for the key. Nil is returned for an open bound.
+func (fb *Filter) keyBound(p *Value) (any, error) {
+	if p != nil && p.Rel {
+		return fb.keyValue(*p)
+	}
+	return rangeBound(fb.newBuildState(), fb.Data, p)
+}
+
 // Hash creates a hash of the filters created
 func (fb *Filter) Hash() string {
 	hasher := sha256.New()
@@ -591,6 +1373,19 @@ func (fb *Filter) Hash() string {
 	return fmt.Sprintf("%x", hashBytes)
 }
 
+// keyList joins the values of a membership list for a key.
+// Unset values are left out as they are when building.
+func keyList(vals []any) string {
+	parts := make([]string, 0, len(vals))
+	for _, val := range vals {
+		if val == nil {
+			continue
+		}
+		parts = append(parts, sanitizeValueForHash(anyToString(val)))
+	}
+	return strings.Join(parts, ",")
+}
+
 func sanitizeColumnForHash(col string) string {
 	if col == "" {
 		return ""
@@ -619,6 +1414,14 @@ func anyToString(value any) string {
 		return ""
 	}
 	switch t := value.(type) {
+	case Null:
+		b = "NULL"
+	case ColumnRef:
+		b = "col(" + string(t) + ")"
+	case ssd.Decimal:
+		b = t.String()
+	case json.Number:
+		b = t.String()
 	case string:
 		b = t
 	case int:
@@ -729,12 +1532,21 @@ func anyToString(value any) string {
 		}
 		tm := *t
 		b = "'" + tm.Format(time.RFC3339) + "'"
+	case driver.Valuer:
+		v, err := t.Value()
+		if err != nil || v == nil {
+			return "NULL"
+		}
+		b = anyToString(v)
 	}
 
 	return b
 }
 
 func isSliceType(v any) bool {
+	if v == nil {
+		return false
+	}
 	t := reflect.TypeOf(v)
 	switch t.Kind() {
 	case reflect.Array:
@@ -1,7 +1,15 @@
 package filterbuilder
 
 import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	ssd "github.com/shopspring/decimal"
 )
 
 func TestNew(t *testing.T) {
@@ -499,3 +507,1244 @@ func TestHash(t *testing.T) {
 		})
 	t.Log(fb.Hash())
 }
+
+func TestInStrategies(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     Dialect
+		sql   string
+		nargs int
+	}{
+		{name: "expand", d: SQLServer, sql: "status IN (@p1,@p2,@p3)", nargs: 3},
+		{name: "array", d: Dialect{Placeholder: "$", InSequence: true, InStrategy: InArray}, sql: "status = ANY($1)", nargs: 1},
+		{name: "table", d: Dialect{Placeholder: "@p", InSequence: true, InStrategy: InTable, TableExpr: "SELECT value FROM OPENJSON(%s)"}, sql: "status IN (SELECT value FROM OPENJSON(@p1))", nargs: 1},
+		{name: "chunk", d: Dialect{Placeholder: ":", InSequence: true, InStrategy: InChunk, ChunkSize: 2}, sql: "(status IN (:1,:2) OR status IN (:3))", nargs: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New(UseDialect(tt.d))
+			fb.In = NewPairs(InRawPair("status", "NEW", "STALE", "OLD"))
+			sql, args, err := fb.Build()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if sql[0] != tt.sql {
+				t.Errorf("got %q, want %q", sql[0], tt.sql)
+			}
+			if len(args) != tt.nargs {
+				t.Errorf("got %d args, want %d", len(args), tt.nargs)
+			}
+		})
+	}
+}
+
+func TestTooManyParameters(t *testing.T) {
+	d := SQLServer
+	d.MaxParams = 3
+	fb := New(UseDialect(d))
+	fb.Eq = NewPairs(EqRawPair("first_name", "Zaldy"))
+	fb.In = NewPairs(InRawPair("status", "NEW", "STALE", "OLD"))
+	if _, _, err := fb.Build(); err != ErrTooManyParameters {
+		t.Errorf("got %v, want %v", err, ErrTooManyParameters)
+	}
+}
+
+func TestEmptyAndNullIn(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Filter
+		sql  []string
+		key  string
+	}{
+		{name: "empty in", f: Filter{In: []In{{Column: "status"}}}, sql: []string{"1=0"}, key: `status=|""`},
+		{name: "empty not in", f: Filter{NotIn: []Ni{{Column: "status"}}}, sql: []string{"1=1"}, key: `status=!|""`},
+		{name: "dropped not in", f: Filter{NotIn: []Ni{{Column: "status"}}, DropEmptyNotIn: true, AllowNoFilters: true}, sql: []string{}, key: ""},
+		{name: "dropped not in only in and", f: Filter{Or: []Or{{Pair: []Filterer{EqRawPair("a", 1), Ni{Column: "status"}}}}, Not: []Not{{Filter: Ni{Column: "status"}}}, DropEmptyNotIn: true}, sql: []string{"(a = ? OR 1=1)", "NOT (1=1)"}, key: `a="1"-status=!|""-!(status=!|"")`},
+		{name: "in with null", f: Filter{In: []In{InRawPair("status", "NEW", nil)}}, sql: []string{"(status IN (?) OR status IS NULL)"}, key: `status=|"NEW,NULL"`},
+		{name: "not in with null", f: Filter{NotIn: []Ni{NiRawPair("status", "NEW", nil)}}, sql: []string{"(status NOT IN (?) AND status IS NOT NULL)"}, key: `status=!|"NEW,NULL"`},
+		{name: "in only null", f: Filter{In: []In{InRawPair("status", nil)}}, sql: []string{"status IS NULL"}, key: `status=|"NULL"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := tt.f.Build()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if strings.Join(sql, " AND ") != strings.Join(tt.sql, " AND ") {
+				t.Errorf("got %q, want %q", sql, tt.sql)
+			}
+			if key := tt.f.MakeKey(); key != tt.key {
+				t.Errorf("got key %q, want %q", key, tt.key)
+			}
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     Range
+		sql   string
+		nargs int
+	}{
+		{name: "closed", r: RangeRawPair("ship_date", "2024-01-01", "2024-02-01"), sql: "ship_date BETWEEN $1 AND $2", nargs: 2},
+		{name: "half open", r: RangeRawPair("ship_date", "2024-01-01", "2024-02-01").Exclusive(false, true), sql: "(ship_date >= $1 AND ship_date < $2)", nargs: 2},
+		{name: "lower only", r: RangeRawPair("ship_date", "2024-01-01", nil), sql: "ship_date >= $1", nargs: 1},
+		{name: "upper only exclusive", r: RangeRawPair("ship_date", nil, "2024-02-01").Exclusive(false, true), sql: "ship_date < $1", nargs: 1},
+		{name: "null bound", r: RangeRawPair("ship_date", Null(true), "2024-02-01"), sql: "ship_date <= $1", nargs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New(UseDialect(Postgres))
+			fb.Range = NewPairs(tt.r)
+			sql, args, err := fb.Build()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if sql[0] != tt.sql {
+				t.Errorf("got %q, want %q", sql[0], tt.sql)
+			}
+			if len(args) != tt.nargs {
+				t.Errorf("got %d args, want %d", len(args), tt.nargs)
+			}
+		})
+	}
+
+	between := []struct {
+		name  string
+		f     Filterer
+		sql   string
+		nargs int
+	}{
+		{name: "not between", f: NbRawPair("age", 18, 65), sql: "age NOT BETWEEN $1 AND $2", nargs: 2},
+		{name: "not between lower only", f: NbRawPair("age", 18, nil), sql: "age < $1", nargs: 1},
+		{name: "not between null upper", f: NbRawPair("age", 18, Null(true)), sql: "age < $1", nargs: 1},
+		{name: "not between upper only", f: NbRawPair("age", nil, 65), sql: "age > $1", nargs: 1},
+		{name: "between lower only", f: BwRawPair("age", 18, nil), sql: "age >= $1", nargs: 1},
+		{name: "between upper only", f: BwRawPair("age", nil, 65), sql: "age <= $1", nargs: 1},
+	}
+	for _, tt := range between {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New(UseDialect(Postgres))
+			switch f := tt.f.(type) {
+			case Bw:
+				fb.Between = NewPairs(f)
+			case Nb:
+				fb.NotBetween = NewPairs(f)
+			}
+			sql, args, err := fb.Build()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if got := strings.Join(strings.Fields(sql[0]), " "); got != tt.sql {
+				t.Errorf("got %q, want %q", got, tt.sql)
+			}
+			if len(args) != tt.nargs {
+				t.Errorf("got %d args, want %d", len(args), tt.nargs)
+			}
+		})
+	}
+
+	fb := New(UseDialect(Postgres), AllowNoFilters(true))
+	fb.NotBetween = NewPairs(NbRawPair("age", nil, nil))
+	if sql, args, err := fb.Build(); err != nil || len(sql) != 0 || len(args) != 0 {
+		t.Errorf("got %q %v %v, want an unset pair to be left out", sql, args, err)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	loc := time.FixedZone("PHT", 8*60*60)
+	now := time.Date(2024, time.March, 14, 10, 30, 0, 0, time.UTC) // Thursday, 18:30 in PHT
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "now", want: now.In(loc)},
+		{expr: "now-7d", want: now.In(loc).AddDate(0, 0, -7)},
+		{expr: "today", want: time.Date(2024, time.March, 14, 0, 0, 0, 0, loc)},
+		{expr: "startOfWeek", want: time.Date(2024, time.March, 11, 0, 0, 0, 0, loc)},
+		{expr: "startOfMonth-1M", want: time.Date(2024, time.February, 1, 0, 0, 0, 0, loc)},
+		{expr: "startOfYear+1y-1d", want: time.Date(2024, time.December, 31, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		fb := New(Clock(func() time.Time { return now }), Location(loc))
+		fb.Gte = NewPairs(Gte{Column: "created_at", Value: Value{Src: tt.expr, Rel: true}})
+		_, args, err := fb.Build()
+		if err != nil {
+			t.Fatalf("%s: %s", tt.expr, err)
+		}
+		if got := args[0].(time.Time); !got.Equal(tt.want) || got.Location() != loc {
+			t.Errorf("%s: got %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	// The key keeps the expression so it does not change with the clock
+	clock := now
+	fb := New(Clock(func() time.Time { clock = clock.Add(time.Second); return clock }))
+	fb.Eq = NewPairs(Eq{Column: "created_at", Value: Value{Src: "now-7d", Rel: true}})
+	fb.Range = NewPairs(RangeRelPair("updated_at", "startOfWeek", "now"))
+	if k1, k2 := fb.MakeKey(), fb.MakeKey(); k1 != k2 || !strings.Contains(k1, "@now-7d") {
+		t.Errorf("got keys %q and %q, want the same key with the expression", k1, k2)
+	}
+
+	fb = New(Clock(func() time.Time { return now }))
+	fb.Gte = NewPairs(Gte{Column: "created_at", Value: Value{Src: "yesterday", Rel: true}})
+	if _, _, err := fb.Build(); err != ErrInvalidRelativeTime {
+		t.Errorf("got %v, want %v", err, ErrInvalidRelativeTime)
+	}
+}
+
+func TestOnDate(t *testing.T) {
+	loc := time.FixedZone("PHT", 8*60*60)
+	fb := New(UseDialect(Postgres))
+	fb.Range = NewPairs(
+		OnDate("created_at", time.Date(2024, time.March, 13, 20, 0, 0, 0, time.UTC), loc),
+		InMonth("ship_date", 2024, time.February, loc),
+	)
+	sql, args, err := fb.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if sql[0] != "(created_at >= $1 AND created_at < $2)" {
+		t.Errorf("got %q", sql[0])
+	}
+	if want := time.Date(2024, time.March, 14, 0, 0, 0, 0, loc); !args[0].(time.Time).Equal(want) {
+		t.Errorf("got %v, want %v", args[0], want)
+	}
+	if want := time.Date(2024, time.March, 1, 0, 0, 0, 0, loc); !args[3].(time.Time).Equal(want) {
+		t.Errorf("got %v, want %v", args[3], want)
+	}
+}
+
+func TestColumnCompare(t *testing.T) {
+	fb := New(UseDialect(Postgres))
+	fb.Gt = NewPairs(GtColPair("a.updated_at", "a.created_at"))
+	if _, _, err := fb.Build(); err != ErrSchemaRequired {
+		t.Errorf("got %v, want %v", err, ErrSchemaRequired)
+	}
+
+	fb.Schema = Schema{"a.updated_at": {}, "a.created_at": {}, "o.ship_date": {}, "o.due_date": {}, "a.status": {}}
+	fb.Lte = NewPairs(LteColPair("o.ship_date", "o.due_date"))
+	fb.Eq = NewPairs(EqRawPair("a.status", "NEW"))
+	sql, args, err := fb.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want := "a.status = $1 AND o.ship_date <= o.due_date AND a.updated_at > a.created_at"
+	if got := strings.Join(sql, " AND "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(args) != 1 {
+		t.Errorf("got %d args, want 1", len(args))
+	}
+
+	var fj Filter
+	err = json.Unmarshal([]byte(`{"gt":[{"column":"updated","value":{"src":"created","col":true}}]}`), &fj)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	fj.Schema = Schema{
+		"updated": {Name: "a.updated_at"},
+		"created": {Name: "a.created_at"},
+	}
+	sql, _, err = fj.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if sql[0] != "a.updated_at > a.created_at" {
+		t.Errorf("got %q", sql[0])
+	}
+
+	fj.Gt[0].Value.Src = "password"
+	if _, _, err = fj.Build(); err != ErrColumnNotAllowed {
+		t.Errorf("got %v, want %v", err, ErrColumnNotAllowed)
+	}
+}
+
+func TestNot(t *testing.T) {
+	grp := Group{And: []Filterer{
+		EqRawPair("status", "NEW"),
+		InRawPair("region", "N", "S"),
+	}}
+	tests := []struct {
+		name     string
+		f        Not
+		pushDown bool
+		sql      string
+	}{
+		{name: "eq", f: Not{Filter: EqRawPair("status", "NEW")}, sql: "NOT (status = $1)"},
+		{name: "group", f: Not{Filter: grp}, sql: "NOT (status = $1 AND region IN ($2,$3))"},
+		{name: "push down eq", f: Not{Filter: EqRawPair("status", "NEW")}, pushDown: true, sql: "status <> $1"},
+		{name: "push down null", f: Not{Filter: EqRawPair("status", nil)}, pushDown: true, sql: "status IS NOT NULL"},
+		{name: "push down in", f: Not{Filter: InRawPair("region", "N", "S")}, pushDown: true, sql: "region NOT IN ($1,$2)"},
+		{name: "push down group", f: Not{Filter: grp}, pushDown: true, sql: "(status <> $1 OR region NOT IN ($2,$3))"},
+		{name: "push down double", f: Not{Filter: Not{Filter: GtRawPair("age", 30)}}, pushDown: true, sql: "age > $1"},
+		{name: "push down lt null", f: Not{Filter: LtRawPair("age", nil)}, pushDown: true, sql: "NOT (age IS NULL)"},
+		{name: "push down group lt null", f: Not{Filter: Group{And: []Filterer{LtRawPair("age", nil)}}}, pushDown: true, sql: "(NOT (age IS NULL))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New(UseDialect(Postgres), PushDownNot(tt.pushDown))
+			fb.Not = NewPairs(tt.f)
+			sql, _, err := fb.Build()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if sql[0] != tt.sql {
+				t.Errorf("got %q, want %q", sql[0], tt.sql)
+			}
+		})
+	}
+}
+
+func TestNotJSON(t *testing.T) {
+	fb := New(UseDialect(Postgres))
+	fb.Not = NewPairs(Not{Filter: Or{Pair: []Filterer{
+		EqRawPair("status", "NEW"),
+		Group{And: []Filterer{GtRawPair("age", 30), LkRawPair("name", "Z%")}},
+	}}})
+	b, err := json.Marshal(fb)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	t.Log(string(b))
+
+	fj := New(UseDialect(Postgres))
+	if err := json.Unmarshal(b, fj); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want, _, _ := fb.Build()
+	got, _, err := fj.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if got[0] != want[0] {
+		t.Errorf("got %q, want %q", got[0], want[0])
+	}
+}
+
+func TestExists(t *testing.T) {
+	fb := New(UseDialect(Postgres), UseRelations(Relations{
+		"lines": {Table: "lines l", On: "l.order_id = o.id"},
+	}))
+	fb.Eq = NewPairs(EqRawPair("o.status", "OPEN"))
+	fb.Exists = NewPairs(Exists{
+		Relation: "lines",
+		Where: &Filter{
+			Eq: NewPairs(EqRawPair("l.sku", "X-100")),
+			In: NewPairs(InRawPair("l.warehouse", "A", "B")),
+		},
+	})
+	fb.NotExists = NewPairs(NotExists{Table: "returns r", On: "r.order_id = o.id"})
+	fb.Gt = NewPairs(GtRawPair("o.total", 100))
+	sql, args, err := fb.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want := "o.status = $1 AND o.total > $2 AND " +
+		"EXISTS (SELECT 1 FROM lines l WHERE l.order_id = o.id AND l.sku = $3 AND l.warehouse IN ($4,$5)) AND " +
+		"NOT EXISTS (SELECT 1 FROM returns r WHERE r.order_id = o.id)"
+	if got := strings.Join(sql, " AND "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(args) != 5 {
+		t.Errorf("got %d args, want 5", len(args))
+	}
+
+	var fj Filter
+	err = json.Unmarshal([]byte(`{"exists":[{"relation":"orders","where":{"eq":[{"column":"sku","value":{"src":"X","raw":true}}]}}]}`), &fj)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if _, _, err = fj.Build(); err != ErrRelationNotFound {
+		t.Errorf("got %v, want %v", err, ErrRelationNotFound)
+	}
+}
+
+func TestInQuery(t *testing.T) {
+	fb := New(UseDialect(SQLServer), Offset(2))
+	fb.Eq = NewPairs(EqRawPair("o.status", "OPEN"))
+	fb.InQuery = NewPairs(InQuery{
+		Column: "o.customer_id",
+		Table:  "customers c",
+		Select: "c.id",
+		Where:  &Filter{Eq: NewPairs(EqRawPair("c.region", "NORTH"))},
+	})
+	fb.NotInQuery = NewPairs(NotInQuery{Column: "o.id", Table: "holds h", Select: "h.order_id"})
+	sql, args, err := fb.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want := "o.status = @p3 AND " +
+		"o.customer_id IN (SELECT c.id FROM customers c WHERE c.region = @p4) AND " +
+		"o.id NOT IN (SELECT h.order_id FROM holds h)"
+	if got := strings.Join(sql, " AND "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(args) != 2 {
+		t.Errorf("got %d args, want 2", len(args))
+	}
+
+	key := fb.MakeKey()
+	fb.InQuery[0].Where.Eq[0].Value.Src = "SOUTH"
+	if fb.MakeKey() == key {
+		t.Errorf("key did not change with the subquery filter: %q", key)
+	}
+
+	// Without a schema the selected column must be an identifier
+	fb = New(UseDialect(SQLServer))
+	fb.InQuery = NewPairs(InQuery{Column: "o.id", Table: "holds h", Select: "h.order_id FROM holds h UNION SELECT id"})
+	if _, _, err := fb.Build(); err != ErrInvalidIdentifier {
+		t.Errorf("got %v, want %v", err, ErrInvalidIdentifier)
+	}
+}
+
+func TestExpr(t *testing.T) {
+	fb := New(UseDialect(Postgres))
+	fb.Eq = NewPairs(EqRawPair("kind", "STORE"))
+	fb.Or = NewPairs(Or{Pair: []Filterer{
+		NewExpr("ST_DWithin(geom, ST_MakePoint(?, ?), ?)", 121.0, 14.6, 500),
+		NewExpr("name = '?' OR tags ?? 'open' OR code = ?", "X"),
+	}})
+	sql, args, err := fb.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want := "kind = $1 AND (ST_DWithin(geom, ST_MakePoint($2, $3), $4) OR name = '?' OR tags ? 'open' OR code = $5)"
+	if got := strings.Join(sql, " AND "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(args) != 5 {
+		t.Errorf("got %d args, want 5", len(args))
+	}
+	if n := len(fb.Exprs()); n != 2 {
+		t.Errorf("got %d exprs, want 2", n)
+	}
+
+	fb.Expr = NewPairs(NewExpr("a = ? AND b = ?", 1))
+	if _, _, err := fb.Build(); err != ErrExprArgCount {
+		t.Errorf("got %v, want %v", err, ErrExprArgCount)
+	}
+
+	// An escaped ? would be read as a placeholder where the placeholder is a plain ?
+	fb = New(UseDialect(MySQL))
+	fb.Expr = NewPairs(NewExpr("tags ?? 'open' AND code = ?", "X"))
+	if _, _, err := fb.Build(); err != ErrExprEscapedMarker {
+		t.Errorf("got %v, want %v", err, ErrExprEscapedMarker)
+	}
+	fb.Expr = NewPairs(NewExpr("name = '??' AND code = ?", "X"))
+	if sql, _, err := fb.Build(); err != nil || sql[0] != "name = '??' AND code = ?" {
+		t.Errorf("got %q %v, want %q", sql, err, "name = '??' AND code = ?")
+	}
+
+	// Brackets are array subscripts in Postgres and quoted identifiers in SQL Server
+	fb = New(UseDialect(Postgres))
+	fb.Expr = NewPairs(NewExpr("tags[?] = ?", 1, "x"))
+	if sql, _, err := fb.Build(); err != nil || sql[0] != "tags[$1] = $2" {
+		t.Errorf("got %q %v, want %q", sql, err, "tags[$1] = $2")
+	}
+	if lit, err := Interpolate("tags[$1] = $2", []any{1, "x"}, Postgres); err != nil || lit != "tags[1] = 'x'" {
+		t.Errorf("got %q %v", lit, err)
+	}
+	fb = New(UseDialect(SQLServer))
+	fb.Expr = NewPairs(NewExpr("[a?b] = ?", 1))
+	if sql, _, err := fb.Build(); err != nil || sql[0] != "[a?b] = @p1" {
+		t.Errorf("got %q %v, want %q", sql, err, "[a?b] = @p1")
+	}
+
+	var fj Filter
+	err = json.Unmarshal([]byte(`{"not":[{"expr":{"SQL":"1=1"}}]}`), &fj)
+	if err != ErrFiltererNotSerializable {
+		t.Errorf("got %v, want %v", err, ErrFiltererNotSerializable)
+	}
+}
+
+func TestTupleIn(t *testing.T) {
+	cols := []string{"tenant_id", "order_no"}
+	tests := []struct {
+		name  string
+		d     Dialect
+		f     TupleIn
+		sql   string
+		nargs int
+	}{
+		{name: "row values", d: Postgres, f: TupleInRawPair(cols, []any{1, "A"}, []any{1, "B"}), sql: "(tenant_id, order_no) IN (($1,$2),($3,$4))", nargs: 4},
+		{name: "expanded", d: SQLServer, f: TupleInRawPair(cols, []any{1, "A"}, []any{1, "B"}), sql: "((tenant_id = @p1 AND order_no = @p2) OR (tenant_id = @p3 AND order_no = @p4))", nargs: 4},
+		{name: "null member", d: Postgres, f: TupleInRawPair(cols, []any{1, "A"}, []any{2, nil}), sql: "((tenant_id, order_no) IN (($1,$2)) OR (tenant_id = $3 AND order_no IS NULL))", nargs: 3},
+		{name: "chunked", d: Dialect{Placeholder: ":", InSequence: true, RowValues: true, InStrategy: InChunk, ChunkSize: 1}, f: TupleInRawPair(cols, []any{1, "A"}, []any{1, "B"}), sql: "((tenant_id, order_no) IN ((:1,:2)) OR (tenant_id, order_no) IN ((:3,:4)))", nargs: 4},
+		{name: "empty", d: Postgres, f: TupleIn{Columns: cols}, sql: "1=0", nargs: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New(UseDialect(tt.d))
+			fb.TupleIn = NewPairs(tt.f)
+			sql, args, err := fb.Build()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if sql[0] != tt.sql {
+				t.Errorf("got %q, want %q", sql[0], tt.sql)
+			}
+			if len(args) != tt.nargs {
+				t.Errorf("got %d args, want %d", len(args), tt.nargs)
+			}
+		})
+	}
+
+	fb := New(UseDialect(Postgres))
+	fb.TupleIn = NewPairs(TupleInRawPair(cols, []any{1}))
+	if _, _, err := fb.Build(); err != ErrTupleSize {
+		t.Errorf("got %v, want %v", err, ErrTupleSize)
+	}
+}
+
+func TestSeek(t *testing.T) {
+	desc := []SeekKey{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}
+	mixed := []SeekKey{{Column: "name"}, {Column: "created_at", Desc: true}, {Column: "id"}}
+	ts := time.Date(2024, time.March, 14, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		d     Dialect
+		f     Seek
+		sql   string
+		nargs int
+	}{
+		{name: "row values", d: Postgres, f: SeekAfter(desc, ts, 42), sql: "(created_at, id) < ($1,$2)", nargs: 2},
+		{name: "no row values", d: SQLServer, f: SeekAfter(desc, ts, 42), sql: "(created_at < @p1 OR (created_at = @p2 AND id < @p3))", nargs: 3},
+		{name: "no row comparison", d: Oracle, f: SeekAfter(desc, ts, 42), sql: "(created_at < :1 OR (created_at = :2 AND id < :3))", nargs: 3},
+		{name: "mixed", d: Postgres, f: SeekAfter(mixed, "Zaldy", ts, 42), sql: "(name > $1 OR (name = $2 AND created_at < $3) OR (name = $4 AND created_at = $5 AND id > $6))", nargs: 6},
+		{name: "single", d: Postgres, f: SeekAfter(desc[1:], 42), sql: "id < $1", nargs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New(UseDialect(tt.d))
+			fb.Seek = NewPairs(tt.f)
+			sql, args, err := fb.Build()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if sql[0] != tt.sql {
+				t.Errorf("got %q, want %q", sql[0], tt.sql)
+			}
+			if len(args) != tt.nargs {
+				t.Errorf("got %d args, want %d", len(args), tt.nargs)
+			}
+		})
+	}
+
+	// Seek positions only come from verified cursors, never from JSON
+	var fj Filter
+	if err := json.Unmarshal([]byte(`{"seek":[{"keys":[{"column":"id"}],"value":[{"src":1,"raw":true}]}]}`), &fj); err != nil || len(fj.Seek) != 0 {
+		t.Errorf("got %v %v, want the seek to be ignored", fj.Seek, err)
+	}
+	err := json.Unmarshal([]byte(`{"not":[{"seek":{"keys":[{"column":"id"}],"value":[{"src":1,"raw":true}]}}]}`), &fj)
+	if err != ErrFiltererNotSerializable {
+		t.Errorf("got %v, want %v", err, ErrFiltererNotSerializable)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	secret := []byte("secret")
+	keys := []SeekKey{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}
+	ts := time.Date(2024, time.March, 14, 10, 30, 0, 0, time.UTC)
+
+	cur, err := EncodeCursor(secret, keys, ts, 42)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	s, err := DecodeCursor(secret, keys, cur)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if got := s.Value[0].Src.(time.Time); !got.Equal(ts) {
+		t.Errorf("got %v, want %v", got, ts)
+	}
+	if got := s.Value[1].Src.(int64); got != 42 {
+		t.Errorf("got %v, want 42", got)
+	}
+
+	if _, err := DecodeCursor([]byte("other"), keys, cur); err != ErrInvalidCursor {
+		t.Errorf("got %v, want %v", err, ErrInvalidCursor)
+	}
+	if _, err := DecodeCursor(secret, keys[1:], cur); err != ErrInvalidCursor {
+		t.Errorf("got %v, want %v", err, ErrInvalidCursor)
+	}
+	if _, err := DecodeCursor(secret, keys, "x"+cur); err != ErrInvalidCursor {
+		t.Errorf("got %v, want %v", err, ErrInvalidCursor)
+	}
+
+	// Without a secret anyone could sign a cursor
+	if _, err := EncodeCursor(nil, keys, ts, 42); err != ErrCursorSecret {
+		t.Errorf("got %v, want %v", err, ErrCursorSecret)
+	}
+	if _, err := DecodeCursor([]byte{}, keys, cur); err != ErrCursorSecret {
+		t.Errorf("got %v, want %v", err, ErrCursorSecret)
+	}
+}
+
+func TestSort(t *testing.T) {
+	sorts, err := ParseSort("-created_at:nulls_last, name")
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want := []Sort{{Column: "created_at", Desc: true, Nulls: NullsLast}, {Column: "name"}}
+	if len(sorts) != 2 || sorts[0] != want[0] || sorts[1] != want[1] {
+		t.Fatalf("got %v, want %v", sorts, want)
+	}
+	if _, err := ParseSort("name:nulls_middle"); err != ErrInvalidSort {
+		t.Errorf("got %v, want %v", err, ErrInvalidSort)
+	}
+
+	tests := []struct {
+		name string
+		d    Dialect
+		want string
+	}{
+		{name: "nulls ordering", d: Postgres, want: "created_at DESC NULLS LAST, name"},
+		{name: "emulated", d: MySQL, want: "CASE WHEN created_at IS NULL THEN 1 ELSE 0 END, created_at DESC, name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New(UseDialect(tt.d))
+			fb.Sort = sorts
+			terms, err := fb.BuildSort()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if got := strings.Join(terms, ", "); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var fj Filter
+	if err := json.Unmarshal([]byte(`{"sort":["-created_at",{"column":"name","nulls":"first"}]}`), &fj); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want = []Sort{{Column: "created_at", Desc: true}, {Column: "name", Nulls: NullsFirst}}
+	if len(fj.Sort) != 2 || fj.Sort[0] != want[0] || fj.Sort[1] != want[1] {
+		t.Fatalf("got %v, want %v", fj.Sort, want)
+	}
+
+	fj.Schema = Schema{"name": {Name: "u.name"}}
+	if _, err := fj.BuildSort(); err != ErrColumnNotAllowed {
+		t.Errorf("got %v, want %v", err, ErrColumnNotAllowed)
+	}
+
+	// Without a schema only identifiers can be sorted by
+	fs := New()
+	if fs.Sort, err = ParseSort("(CASE WHEN 1=1 THEN name END)"); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if _, err := fs.BuildSort(); err != ErrInvalidIdentifier {
+		t.Errorf("got %v, want %v", err, ErrInvalidIdentifier)
+	}
+
+	fa := New()
+	fa.Eq = NewPairs(EqRawPair("id", 1))
+	fb := New()
+	fb.Eq = NewPairs(EqRawPair("id", 1))
+	fb.Sort = sorts
+	if fa.MakeKey() == fb.MakeKey() {
+		t.Errorf("sort not in key: %q", fb.MakeKey())
+	}
+}
+
+func TestSelect(t *testing.T) {
+	top := Dialect{Name: "access", Placeholder: "?", Paging: PagingTop}
+	tests := []struct {
+		name   string
+		d      Dialect
+		limit  int
+		offset int
+		sort   []Sort
+		want   string
+		count  string
+	}{
+		{name: "limit offset", d: Postgres, limit: 20, offset: 40, sort: []Sort{{Column: "o.id", Desc: true}}, want: "SELECT o.id, c.name FROM orders o JOIN customers c ON c.id = o.customer_id WHERE o.status = $1 ORDER BY o.id DESC LIMIT 20 OFFSET 40", count: "SELECT COUNT(*) FROM orders o JOIN customers c ON c.id = o.customer_id WHERE o.status = $1"},
+		{name: "offset fetch", d: SQLServer, limit: 20, offset: 40, want: "SELECT o.id, c.name FROM orders o JOIN customers c ON c.id = o.customer_id WHERE o.status = @p1 ORDER BY (SELECT NULL) OFFSET 40 ROWS FETCH NEXT 20 ROWS ONLY", count: "SELECT COUNT(*) FROM orders o JOIN customers c ON c.id = o.customer_id WHERE o.status = @p1"},
+		{name: "top", d: top, limit: 20, sort: []Sort{{Column: "o.id"}}, want: "SELECT TOP 20 o.id, c.name FROM orders o JOIN customers c ON c.id = o.customer_id WHERE o.status = ? ORDER BY o.id", count: "SELECT COUNT(*) FROM orders o JOIN customers c ON c.id = o.customer_id WHERE o.status = ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New(UseDialect(tt.d))
+			fb.Eq = NewPairs(EqRawPair("o.status", "open"))
+			fb.Sort = tt.sort
+			s := Select{
+				Columns: []string{"o.id", "c.name"},
+				From:    "orders o",
+				Joins:   []string{"JOIN customers c ON c.id = o.customer_id"},
+				Filter:  fb,
+				Limit:   tt.limit,
+				Offset:  tt.offset,
+			}
+			sql, args, err := s.Build()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if sql != tt.want {
+				t.Errorf("got %q, want %q", sql, tt.want)
+			}
+			csql, cargs, err := s.BuildCount()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if csql != tt.count {
+				t.Errorf("got %q, want %q", csql, tt.count)
+			}
+			if len(args) != 1 || len(cargs) != 1 {
+				t.Errorf("got %d and %d args, want 1", len(args), len(cargs))
+			}
+
+			// The statement builds the same every time
+			if again, _, err := s.Build(); err != nil || again != sql {
+				t.Errorf("got %q %v, want %q", again, err, sql)
+			}
+			if fb.Offset != 0 {
+				t.Errorf("got offset %d, want 0", fb.Offset)
+			}
+		})
+	}
+
+	fb := New(UseDialect(top))
+	fb.Eq = NewPairs(EqRawPair("o.status", "open"))
+	s := Select{From: "orders o", Filter: fb, Limit: 20, Offset: 20}
+	if _, _, err := s.Build(); err != ErrOffsetNotSupported {
+		t.Errorf("got %v, want %v", err, ErrOffsetNotSupported)
+	}
+}
+
+func TestWeld(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		args []any
+		want string
+		pos  int
+		d    *Dialect
+	}{
+		{name: "append", sql: "SELECT * FROM users;", want: "SELECT * FROM users WHERE status = ?"},
+		{name: "existing where", sql: "SELECT * FROM users WHERE active = 1 OR admin = 1 ORDER BY name", want: "SELECT * FROM users WHERE (active = 1 OR admin = 1) AND status = ? ORDER BY name"},
+		{name: "trailing clauses", sql: "SELECT dept, COUNT(*) FROM users GROUP BY dept HAVING COUNT(*) > ? LIMIT 10", args: []any{5}, want: "SELECT dept, COUNT(*) FROM users WHERE status = ? GROUP BY dept HAVING COUNT(*) > ? LIMIT 10", pos: 0},
+		{name: "for update", sql: "SELECT * FROM users WHERE id > ? FOR UPDATE", args: []any{7}, want: "SELECT * FROM users WHERE (id > ?) AND status = ? FOR UPDATE", pos: 1},
+		{name: "ignored keywords", sql: "SELECT (SELECT MAX(x) FROM t WHERE y = 'order by') AS m FROM users -- where\n", want: "SELECT (SELECT MAX(x) FROM t WHERE y = 'order by') AS m FROM users -- where\n WHERE status = ?"},
+		{name: "marker", sql: "SELECT * FROM (SELECT * FROM users WHERE /*filter*/) u WHERE u.id > ?", args: []any{7}, want: "SELECT * FROM (SELECT * FROM users WHERE status = ?) u WHERE u.id > ?", pos: 0},
+		{name: "returning", sql: "DELETE FROM users WHERE b = ? RETURNING id", args: []any{2}, want: "DELETE FROM users WHERE (b = ?) AND status = ? RETURNING id", pos: 1},
+		{name: "union marker", sql: "SELECT a FROM t WHERE /*filter*/ UNION SELECT a FROM u", want: "SELECT a FROM t WHERE status = ? UNION SELECT a FROM u"},
+		{name: "backslash escapes", sql: `SELECT * FROM users WHERE name = 'it\'s' ORDER BY x`, want: `SELECT * FROM users WHERE (name = 'it\'s') AND status = ? ORDER BY x`, d: &MySQL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New()
+			if tt.d != nil {
+				fb = New(UseDialect(*tt.d))
+			}
+			fb.Eq = NewPairs(EqRawPair("status", "active"))
+			sql, args, err := fb.Weld(tt.sql, tt.args, 0)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if sql != tt.want {
+				t.Errorf("got %q, want %q", sql, tt.want)
+			}
+			if len(args) != len(tt.args)+1 || args[tt.pos] != "active" {
+				t.Errorf("got args %v, want the filter argument at %d", args, tt.pos)
+			}
+		})
+	}
+
+	// The filter would apply to one of the queries only
+	fb := New()
+	fb.Eq = NewPairs(EqRawPair("status", "active"))
+	for _, sql := range []string{
+		"SELECT a FROM t WHERE x = 1 UNION SELECT a FROM u",
+		"SELECT a FROM t GROUP BY a EXCEPT SELECT a FROM u",
+	} {
+		if _, _, err := fb.Weld(sql, nil, 0); err != ErrWeldSetOperator {
+			t.Errorf("%s: got %v, want %v", sql, err, ErrWeldSetOperator)
+		}
+	}
+
+	fb = New(AllowNoFilters(true))
+	sql, _, err := fb.Weld("SELECT * FROM users WHERE /*filter*/ ORDER BY name", nil, 0)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if want := "SELECT * FROM users WHERE 1=1 ORDER BY name"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+}
+
+func TestHaving(t *testing.T) {
+	fb := New(UseDialect(Postgres), UseAggregates(Schema{
+		"total_amount": {Name: "SUM(o.amount)"},
+		"orders":       {Name: "COUNT(*)"},
+	}))
+	fb.Eq = NewPairs(EqRawPair("o.status", "paid"))
+	fb.Having = &Filter{
+		Gt:  NewPairs(GtRawPair("total_amount", 1000)),
+		Gte: NewPairs(GteRawPair("orders", 3)),
+	}
+
+	s := Select{
+		Columns: []string{"o.customer_id", "SUM(o.amount)"},
+		From:    "orders o",
+		GroupBy: []string{"o.customer_id"},
+		Filter:  fb,
+	}
+	sql, _, err := s.BuildCount()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want := "SELECT COUNT(*) FROM (SELECT 1 AS n FROM orders o WHERE o.status = $1 GROUP BY o.customer_id HAVING SUM(o.amount) > $2 AND COUNT(*) >= $3) t"
+	if sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+
+	c, err := fb.BuildClauses()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if got, want := strings.Join(c.Where, " AND "), "o.status = $1"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := strings.Join(c.Having, " AND "), "SUM(o.amount) > $2 AND COUNT(*) >= $3"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(c.Args) != 3 {
+		t.Errorf("got %d args, want 3", len(c.Args))
+	}
+
+	fb.Having.Lt = NewPairs(LtRawPair("amount", 10))
+	if _, err := fb.BuildClauses(); err != ErrColumnNotAllowed {
+		t.Errorf("got %v, want %v", err, ErrColumnNotAllowed)
+	}
+
+	// Without a registry the HAVING conditions would be inlined as they are
+	fb = New(UseDialect(Postgres))
+	fb.Eq = NewPairs(EqRawPair("o.status", "paid"))
+	fb.Having = &Filter{Gt: NewPairs(GtRawPair("1=1 OR SUM(o.amount)", 0))}
+	if _, err := fb.BuildClauses(); err != ErrSchemaRequired {
+		t.Errorf("got %v, want %v", err, ErrSchemaRequired)
+	}
+}
+
+func TestBuildUpdateDelete(t *testing.T) {
+	type req struct {
+		ID     *int
+		Tenant *string
+	}
+	id, tenant := 7, "acme"
+
+	fb := New(UseDialect(Postgres), RequireColumns("tenant_id"))
+	fb.Data = req{ID: &id, Tenant: &tenant}
+	fb.Eq = NewPairs(EqDataPair("id", "ID"), EqDataPair("tenant_id", "Tenant"))
+	sql, args, err := fb.BuildUpdate("users", []Set{SetRawPair("name", "Zaldy"), SetRawPair("deleted_at", Null(true))})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if want := "UPDATE users SET name = $1, deleted_at = NULL WHERE id = $2 AND tenant_id = $3"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+	if len(args) != 3 || args[0] != "Zaldy" {
+		t.Errorf("got args %v", args)
+	}
+
+	// The required tenant is unset, so the statement is refused
+	fb.Data = req{ID: &id}
+	if _, _, err := fb.BuildDelete("users"); err != ErrRequiredColumn {
+		t.Errorf("got %v, want %v", err, ErrRequiredColumn)
+	}
+
+	// A filter of unset data fields builds to nothing, so the statement is refused
+	fb = New(UseDialect(Postgres), AllowNoFilters(true))
+	fb.Data = req{}
+	fb.Eq = NewPairs(EqDataPair("id", "ID"))
+	if _, _, err := fb.BuildDelete("users"); err != ErrNoFilterSet {
+		t.Errorf("got %v, want %v", err, ErrNoFilterSet)
+	}
+
+	// Filters of unset data fields that build to an always true condition are refused as well
+	fb = New(UseDialect(Postgres))
+	fb.Data = req{}
+	fb.NotIn = NewPairs(NiDataPair("id", "ID"))
+	if sql, _, err := fb.BuildDelete("users"); err != ErrNoFilterSet {
+		t.Errorf("got %q %v, want %v", sql, err, ErrNoFilterSet)
+	}
+	fb = New(UseDialect(Postgres))
+	fb.Data = req{}
+	fb.Not = NewPairs(Not{Filter: InDataPair("id", "ID")})
+	if sql, _, err := fb.BuildUpdate("users", []Set{SetRawPair("name", "x")}); err != ErrNoFilterSet {
+		t.Errorf("got %q %v, want %v", sql, err, ErrNoFilterSet)
+	}
+
+	fb = New(UseDialect(Postgres))
+	fb.Eq = NewPairs(EqRawPair("id", 7))
+	if _, _, err := fb.BuildUpdate("users", []Set{SetRawPair("name = 'x', role", "admin")}); err != ErrInvalidIdentifier {
+		t.Errorf("got %v, want %v", err, ErrInvalidIdentifier)
+	}
+
+	for cond, want := range map[string]bool{
+		"1=1":                      true,
+		"NOT (1=0)":                true,
+		"(a = $1 OR 1=1)":          true,
+		"NOT (1=1)":                false,
+		"a = $1":                   false,
+		"(a IN ($1,$2) OR 1=0)":    false,
+		"a IS NOT NULL AND 1=1":    false,
+		"name = '1=1' OR code = 1": false,
+	} {
+		if got := alwaysTrue(cond, nil); got != want {
+			t.Errorf("%s: got %v, want %v", cond, got, want)
+		}
+	}
+}
+
+func TestBuildNamed(t *testing.T) {
+	fb := New(Placeholder("@p"), InSequence(true))
+	fb.Eq = NewPairs(EqRawPair("u.first_name", "Zaldy"))
+	fb.Or = NewPairs(Or{Pair: []Filterer{
+		EqRawPair("first_name", "James"),
+		Group{And: []Filterer{
+			InRawPair("status", "active", "pending"),
+			GteRawPair("created_at", "2024-01-01"),
+		}},
+	}})
+
+	qry, args, err := fb.BuildNamed()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want := "u.first_name = @first_name_1 AND (first_name = @first_name_2 OR (status IN (@status_1,@status_2) AND created_at >= @created_at_1))"
+	if got := strings.Join(qry, " AND "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	names := []string{"first_name_1", "first_name_2", "status_1", "status_2", "created_at_1"}
+	if len(args) != len(names) {
+		t.Fatalf("got %d args, want %d", len(args), len(names))
+	}
+	for i, a := range args {
+		if a.Name != names[i] {
+			t.Errorf("got %q, want %q", a.Name, names[i])
+		}
+	}
+	if args[1].Value != "James" || args[3].Value != "pending" {
+		t.Errorf("got args %v", args)
+	}
+}
+
+func TestDedupArgs(t *testing.T) {
+	term := "%acme%"
+	build := func(d Dialect) ([]string, []any) {
+		fb := New(UseDialect(d), DedupArgs(true), Offset(1))
+		fb.Eq = NewPairs(EqRawPair("tenant_id", 7))
+		fb.Or = NewPairs(Or{Pair: []Filterer{
+			LkRawPair("name", term),
+			LkRawPair("email", term),
+			Group{And: []Filterer{EqRawPair("owner_tenant_id", 7), LkRawPair("notes", term)}},
+		}})
+		sql, args, err := fb.Build()
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		return sql, args
+	}
+
+	sql, args := build(Postgres)
+	want := "tenant_id = $2 AND (name LIKE $3 OR email LIKE $3 OR (owner_tenant_id = $2 AND notes LIKE $3))"
+	if got := strings.Join(sql, " AND "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(args) != 2 || args[0] != 7 || args[1] != term {
+		t.Errorf("got args %v", args)
+	}
+
+	// Positional placeholders cannot be shared
+	_, args = build(MySQL)
+	if len(args) != 5 {
+		t.Errorf("got %d args, want 5", len(args))
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	ts := time.Date(2024, time.March, 14, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		d    Dialect
+		want string
+	}{
+		{name: "postgres", d: Postgres, want: `name = 'O''Brien \x' AND active = TRUE AND deleted_at IS NULL AND created_at >= '2024-03-14 10:30:00Z' AND photo <> '\x0aff' AND id IN (1,2)`},
+		{name: "mysql", d: MySQL, want: `name = 'O''Brien \\x' AND active = TRUE AND deleted_at IS NULL AND created_at >= '2024-03-14 10:30:00' AND photo <> X'0aff' AND id IN (1,2)`},
+		{name: "sqlserver", d: SQLServer, want: `name = N'O''Brien \x' AND active = 1 AND deleted_at IS NULL AND created_at >= '2024-03-14T10:30:00' AND photo <> 0x0aff AND id IN (1,2)`},
+		{name: "oracle", d: Oracle, want: `name = 'O''Brien \x' AND active = 0 AND deleted_at IS NULL AND created_at >= TIMESTAMP '2024-03-14 10:30:00' AND photo <> HEXTORAW('0aff') AND id IN (1,2)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := New(UseDialect(tt.d))
+			fb.Eq = NewPairs(EqRawPair("name", `O'Brien \x`), EqRawPair("active", tt.name != "oracle"), EqRawPair("deleted_at", Null(true)))
+			fb.Gte = NewPairs(GteRawPair("created_at", ts))
+			fb.In = NewPairs(InRawPair("id", 1, 2))
+			fb.Ne = NewPairs(NeRawPair("photo", []byte{0x0a, 0xff}))
+			got, err := fb.Interpolate()
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+
+	sql, err := Interpolate("SELECT * FROM t WHERE a = $2 AND b = $1 AND c = '$1'", []any{1.5, "x"}, Postgres)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if want := "SELECT * FROM t WHERE a = 'x' AND b = 1.5 AND c = '$1'"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+	// The escaped quote of MySQL does not end the literal, so its ? is not a placeholder
+	sql, err = Interpolate(`SELECT * FROM t WHERE c = 'it\'s?' AND a = ?`, []any{1}, MySQL)
+	if want := `SELECT * FROM t WHERE c = 'it\'s?' AND a = 1`; err != nil || sql != want {
+		t.Errorf("got %q %v, want %q", sql, err, want)
+	}
+	if _, err := Interpolate("a = ? AND b = ?", []any{1}, MySQL); err != ErrTooFewArguments {
+		t.Errorf("got %v, want %v", err, ErrTooFewArguments)
+	}
+	if _, err := Interpolate("a = $1 AND b = $3", []any{1, 2}, Postgres); err != ErrTooFewArguments {
+		t.Errorf("got %v, want %v", err, ErrTooFewArguments)
+	}
+
+	// Interpolating for a log line leaves the filter as it is
+	fb := New(UseDialect(Postgres))
+	fb.Eq = NewPairs(EqRawPair("a", 1))
+	if _, err := fb.Interpolate(); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if _, err := fb.BuildLiteral(); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if sql, args, err := fb.Build(); err != nil || sql[0] != "a = $1" || len(args) != 1 {
+		t.Errorf("got %q %v %v, want %q", sql, args, err, "a = $1")
+	}
+}
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		from, to Dialect
+		offset   int
+		want     string
+	}{
+		{name: "positional to numbered", sql: "SELECT * FROM t WHERE a = ? AND b = '?' AND c ?? 'k' /* ? */ AND d = ?", from: MySQL, to: Postgres, offset: 2, want: "SELECT * FROM t WHERE a = $3 AND b = '?' AND c ? 'k' /* ? */ AND d = $4"},
+		{name: "numbered to numbered", sql: "a = $1 AND b = $2 AND c = $1", from: Postgres, to: SQLServer, want: "a = @p1 AND b = @p2 AND c = @p1"},
+		{name: "numbered to positional", sql: "a = @p1 AND b = @p2", from: SQLServer, to: SQLite, want: "a = ? AND b = ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Rebind(tt.sql, tt.from, tt.to, tt.offset)
+			if err != nil {
+				t.Fatalf("Error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if _, err := Rebind("a = $2 AND b = $1", Postgres, MySQL, 0); err != ErrRebindOrder {
+		t.Errorf("got %v, want %v", err, ErrRebindOrder)
+	}
+
+	fb := New(UseDialect(Postgres), RebindBase(MySQL))
+	fb.Eq = NewPairs(EqRawPair("status", "active"))
+	sql, args, err := fb.Weld("SELECT * FROM users WHERE tenant_id = ? ORDER BY name", []any{7}, 1)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if want := "SELECT * FROM users WHERE (tenant_id = $1) AND status = $2 ORDER BY name"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+	if len(args) != 2 || args[0] != 7 {
+		t.Errorf("got args %v", args)
+	}
+}
+
+func TestArgConverters(t *testing.T) {
+	type status int
+	const active status = 2
+	id := [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	ts := time.Date(2024, time.March, 14, 18, 30, 0, 0, time.FixedZone("PHT", 8*3600))
+
+	fb := New(UseDialect(Oracle), ConvertArgs(EnumToInt, DecimalToString), UseSchema(Schema{
+		"id":         {Convert: UUIDToString},
+		"created_at": {Convert: TimeToUTC},
+		"status":     {},
+		"archived":   {},
+		"amount":     {},
+	}))
+	fb.Eq = NewPairs(EqRawPair("id", id), EqRawPair("status", active), EqRawPair("archived", false))
+	fb.Gte = NewPairs(GteRawPair("created_at", ts), GteRawPair("amount", ssd.NewFromFloat(12.5)))
+
+	_, args, err := fb.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want := []any{"123e4567-e89b-12d3-a456-426614174000", int64(2), 0, ts.UTC(), "12.5"}
+	if len(args) != len(want) {
+		t.Fatalf("got %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("arg %d: got %#v, want %#v", i, args[i], want[i])
+		}
+	}
+}
+
+func TestValuers(t *testing.T) {
+	type req struct {
+		Name    sql.NullString
+		Age     sql.NullInt64
+		Manager sql.Null[int]
+		Amount  ssd.Decimal
+	}
+	fb := New(UseDialect(Postgres))
+	fb.Data = req{
+		Name:    sql.NullString{String: "Zaldy", Valid: true},
+		Manager: sql.Null[int]{},
+		Amount:  ssd.NewFromInt(5),
+	}
+	fb.Eq = NewPairs(EqDataPair("name", "Name"), EqDataPair("age", "Age"), EqDataPair("manager_id", "Manager"), EqDataPair("amount", "Amount"))
+
+	sql, args, err := fb.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	want := "name = $1 AND age IS NULL AND manager_id IS NULL AND amount = $2"
+	if got := strings.Join(sql, " AND "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(args) != 2 || args[0] != "Zaldy" {
+		t.Errorf("got args %v", args)
+	}
+	if _, ok := args[1].(ssd.Decimal); !ok {
+		t.Errorf("got %T, want decimal", args[1])
+	}
+
+	key := fb.MakeKey()
+	if !strings.Contains(key, `name="Zaldy"`) || !strings.Contains(key, `age="NULL"`) || !strings.Contains(key, `amount="5"`) {
+		t.Errorf("got key %q", key)
+	}
+}
+
+func TestColumnTypes(t *testing.T) {
+	schema := Schema{
+		"id":       {Type: TypeInt},
+		"amount":   {Type: TypeDecimal},
+		"active":   {Type: TypeBool},
+		"since":    {Type: TypeDate},
+		"ref":      {Type: TypeUUID},
+		"status":   {Type: TypeEnum, Values: []string{"open", "closed"}},
+		"note":     {Type: TypeString},
+		"quantity": {},
+	}
+	src := `{"eq":[
+		{"column":"id","value":{"src":42,"raw":true}},
+		{"column":"amount","value":{"src":12345678901234567.89,"raw":true}},
+		{"column":"active","value":{"src":"true","raw":true}},
+		{"column":"since","value":{"src":"2024-03-14","raw":true}},
+		{"column":"ref","value":{"src":"123E4567E89B12D3A456426614174000","raw":true}},
+		{"column":"status","value":{"src":"open","raw":true}},
+		{"column":"quantity","value":{"src":3,"raw":true}}
+	]}`
+	fb := New(UseDialect(Postgres), UseSchema(schema), Location(time.UTC))
+	if err := json.Unmarshal([]byte(src), fb); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	_, args, err := fb.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if args[0] != int64(42) {
+		t.Errorf("got %#v, want int64 42", args[0])
+	}
+	if d, ok := args[1].(ssd.Decimal); !ok || d.String() != "12345678901234567.89" {
+		t.Errorf("got %#v, want the exact decimal", args[1])
+	}
+	if args[2] != true {
+		t.Errorf("got %#v, want true", args[2])
+	}
+	if !args[3].(time.Time).Equal(time.Date(2024, time.March, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %v", args[3])
+	}
+	if args[4] != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Errorf("got %#v", args[4])
+	}
+	if args[6] != int64(3) {
+		t.Errorf("got %#v, want int64 3", args[6])
+	}
+
+	id, err := ValueFor[int](fb, "id")
+	if err != nil || id != 42 {
+		t.Errorf("got %v, %v, want 42", id, err)
+	}
+
+	// The elements of a JSON list are converted to the element type
+	fl := New(UseSchema(schema))
+	if err := json.Unmarshal([]byte(`{"in":[{"column":"id","value":[{"src":1,"raw":true},{"src":2,"raw":true}]}]}`), fl); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if ids, err := ValueFor[[]int](fl, "id"); err != nil || !slices.Equal(ids, []int{1, 2}) {
+		t.Errorf("got %v, %v, want [1 2]", ids, err)
+	}
+	if _, err := ValueFor[[]bool](fl, "id"); err != ErrDataAssertionMismatch {
+		t.Errorf("got %v, want %v", err, ErrDataAssertionMismatch)
+	}
+	if _, err := ValueFor[int](fl, "id"); err != ErrDataAssertionMismatch {
+		t.Errorf("got %v, want %v", err, ErrDataAssertionMismatch)
+	}
+
+	for _, bad := range []Eq{EqRawPair("status", "deleted"), EqRawPair("id", 1.5), EqRawPair("ref", "not-a-uuid")} {
+		fb := New(UseSchema(schema))
+		fb.Eq = NewPairs(bad)
+		if _, _, err := fb.Build(); err != ErrEnumValue && err != ErrValueType {
+			t.Errorf("%s: got %v, want a coercion error", bad.Column, err)
+		}
+	}
+}
+
+func TestCol(t *testing.T) {
+	var (
+		Age  = Col[int]("age")
+		Name = NewTextCol("name")
+		Nick = Col[string]("nick")
+	)
+
+	schema := Schema{"age": {}, "name": {}, "nick": {}}
+	typed := New(UseDialect(Postgres), UseSchema(schema))
+	typed.Eq = NewPairs(Age.Eq(30), Name.EqCol(Nick), Nick.IsNull())
+	typed.Lk = NewPairs(Name.Like("Jo%"))
+	typed.In = NewPairs(Age.In(1, 2))
+	typed.Between = NewPairs(Age.Between(18, 65))
+
+	plain := New(UseDialect(Postgres), UseSchema(schema))
+	plain.Eq = NewPairs(EqRawPair("age", 30), EqColPair("name", "nick"), EqRawPair("nick", Null(true)))
+	plain.Lk = NewPairs(LkRawPair("name", "Jo%"))
+	plain.In = NewPairs(InRawPair("age", 1, 2))
+	plain.Between = NewPairs(BwRawPair("age", 18, 65))
+
+	tq, targs, err := typed.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	pq, pargs, err := plain.Build()
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if !reflect.DeepEqual(tq, pq) || !reflect.DeepEqual(targs, pargs) {
+		t.Errorf("got %v %v, want %v %v", tq, targs, pq, pargs)
+	}
+	if Age.Name() != "age" || Age.Desc() != (Sort{Column: "age", Desc: true}) {
+		t.Errorf("got %s %+v", Age.Name(), Age.Desc())
+	}
+}
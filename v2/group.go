@@ -1,6 +1,7 @@
 package filterbuilder
 
 import (
+	"encoding/json"
 	"strings"
 )
 
@@ -13,17 +14,23 @@ func (g Group) GetPair() any {
 }
 
 func (g Group) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return g.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (g Group) build(bs *buildState, data any, offset int) (string, any, int, error) {
 	parts := []string{}
 	args := []any{}
 
 	for _, f := range g.And {
-		str, rv, newOffset, err := f.Build(data, ph, inSeq, offset)
+		str, rv, newOffset, err := buildFilterer(f, bs, data, offset)
 		if err != nil {
 			return "", nil, offset, err
 		}
 		offset = newOffset
 
-		parts = append(parts, str)
+		if str != "" {
+			parts = append(parts, str)
+		}
 
 		if isSliceType(rv) {
 			args = append(args, rv.([]any)...)
@@ -32,5 +39,33 @@ func (g Group) Build(data any, ph string, inSeq bool, offset int) (string, any,
 		}
 	}
 
+	if len(parts) == 0 {
+		return "", args, offset, nil
+	}
 	return "(" + strings.Join(parts, " AND ") + ")", args, offset, nil
 }
+
+func (g Group) MarshalJSON() ([]byte, error) {
+	and, err := marshalFilterers(g.And)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		And []json.RawMessage `json:"and"`
+	}{and})
+}
+
+func (g *Group) UnmarshalJSON(b []byte) error {
+	var v struct {
+		And []json.RawMessage `json:"and"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	and, err := unmarshalFilterers(v.And)
+	if err != nil {
+		return err
+	}
+	g.And = and
+	return nil
+}
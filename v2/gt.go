@@ -1,8 +1,8 @@
 package filterbuilder
 
 type Gt struct {
-	Column string
-	Value  Value
+	Column string `json:"column,omitempty"` // Database table column
+	Value  Value  `json:"value,omitempty"`  // Struct field to get value or the value itself
 }
 
 func GtRawPair(column string, value any) Gt {
@@ -19,10 +19,23 @@ func GtDataPair(column string, field string) Gt {
 	}
 }
 
+// GtColPair simplifies column to column Gt pair.
+// Pairs compares the column with the other column instead of a value.
+func GtColPair(column string, other string) Gt {
+	return Gt{
+		Column: column,
+		Value:  Value{Src: other, Col: true},
+	}
+}
+
 func (f Gt) GetPair() any {
 	return f
 }
 
 func (f Gt) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildPair(f, data, ">", ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Gt) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildPair(f, data, ">", bs, offset)
 }
@@ -1,8 +1,8 @@
 package filterbuilder
 
 type Gte struct {
-	Column string
-	Value  Value
+	Column string `json:"column,omitempty"` // Database table column
+	Value  Value  `json:"value,omitempty"`  // Struct field to get value or the value itself
 }
 
 func GteRawPair(column string, value any) Gte {
@@ -19,10 +19,23 @@ func GteDataPair(column string, field string) Gte {
 	}
 }
 
+// GteColPair simplifies column to column Gte pair.
+// Pairs compares the column with the other column instead of a value.
+func GteColPair(column string, other string) Gte {
+	return Gte{
+		Column: column,
+		Value:  Value{Src: other, Col: true},
+	}
+}
+
 func (f Gte) GetPair() any {
 	return f
 }
 
 func (f Gte) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildPair(f, data, ">=", ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Gte) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildPair(f, data, ">=", bs, offset)
 }
@@ -0,0 +1,48 @@
+package filterbuilder
+
+// Clauses are the conditions of the WHERE and HAVING clauses of a statement and their arguments
+type Clauses struct {
+	Where  []string // Conditions of the WHERE clause
+	Having []string // Conditions of the HAVING clause
+	Args   []any    // Arguments of both clauses, the WHERE arguments first
+}
+
+// BuildClauses builds the WHERE conditions of the filter and the HAVING conditions of its Having filter.
+// The parameters of the HAVING conditions are numbered after those of the WHERE conditions.
+// The columns of the Having filter are checked against the Aggregates of the filter.
+// Since HAVING conditions are usually over expressions, a Having filter requires Aggregates or a Schema.
+func (fb *Filter) BuildClauses() (Clauses, error) {
+	var (
+		c   Clauses
+		err error
+	)
+
+	start := fb.Offset
+
+	fb.defaultPlaceholder()
+	if fb.empty() && (fb.Having == nil || fb.Having.empty()) && !fb.AllowNoFilters {
+		return c, ErrNoFilterSet
+	}
+
+	bs := fb.newBuildState()
+	c.Where, c.Args, err = fb.build(bs)
+	if err != nil || fb.Having == nil {
+		return c, err
+	}
+
+	if fb.Aggregates == nil && fb.Having.Schema == nil && fb.Schema == nil {
+		return c, ErrSchemaRequired
+	}
+
+	var hargs []any
+	c.Having, hargs, fb.Offset, err = buildSubfilter(fb.Having, fb.Aggregates, bs, fb.Data, fb.Offset)
+	if err != nil {
+		return c, err
+	}
+	c.Args = append(c.Args, hargs...)
+
+	n := len(c.Where)
+	qry, args := fb.dedup(bs, append(c.Where[:n:n], c.Having...), c.Args, start)
+	c.Where, c.Having, c.Args = qry[:n], qry[n:], args
+	return c, fb.checkParams(start + len(c.Args))
+}
@@ -38,8 +38,13 @@ func InDataPair(column string, fieldName ...string) In {
 }
 
 func (f In) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildMembershipPair(f, data, "IN", ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
 }
+
+func (f In) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildMembershipPair(f, data, "IN", bs, offset)
+}
+
 func (f In) GetPair() any {
 	return f
 }
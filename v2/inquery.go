@@ -0,0 +1,92 @@
+package filterbuilder
+
+import "strings"
+
+// InQuery is IN in SQL with a list from a subquery: col IN (SELECT sel FROM table WHERE ...).
+//
+// The table is either a registered Relation or, from Go only, a Table.
+type InQuery struct {
+	Column   string  `json:"column,omitempty"`   // Database table column
+	Relation string  `json:"relation,omitempty"` // Name of the relation in the Relations of the Filter
+	Table    string  `json:"-"`                  // Table with its alias when no Relation is set
+	Select   string  `json:"select,omitempty"`   // Column selected by the subquery
+	Where    *Filter `json:"where,omitempty"`    // Filter of the subquery. Data defaults to the Data of the outer filter.
+}
+
+// NotInQuery is NOT IN in SQL with a list from a subquery.
+// As with NOT IN, no row matches when the subquery returns a NULL.
+type NotInQuery InQuery
+
+func (f InQuery) GetPair() any {
+	return f
+}
+
+func (f InQuery) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f InQuery) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildInQuery(f, "IN", bs, data, offset)
+}
+
+func (f NotInQuery) GetPair() any {
+	return f
+}
+
+func (f NotInQuery) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f NotInQuery) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildInQuery(InQuery(f), "NOT IN", bs, data, offset)
+}
+
+func buildInQuery(f InQuery, operator string, bs *buildState, data any, offset int) (string, []any, int, error) {
+	col, err := bs.column(f.Column)
+	if err != nil {
+		return "", nil, offset, err
+	}
+	rel, err := bs.relation(f.Relation, f.Table, "")
+	if err != nil {
+		return "", nil, offset, err
+	}
+
+	// The selected column belongs to the table of the subquery
+	sbs := *bs
+	switch {
+	case f.Where != nil && f.Where.Schema != nil:
+		sbs.schema = f.Where.Schema
+	case rel.Schema != nil:
+		sbs.schema = rel.Schema
+	}
+	sel, err := sbs.schema.identifier(f.Select)
+	if err != nil {
+		return "", nil, offset, err
+	}
+	if sel == "" {
+		return "", nil, offset, ErrColumnNotFound
+	}
+
+	conds := make([]string, 0, 10)
+	if rel.On != "" {
+		conds = append(conds, rel.On)
+	}
+
+	var (
+		sql  []string
+		args = []any{}
+	)
+	if f.Where != nil {
+		sql, args, offset, err = buildSubfilter(f.Where, rel.Schema, bs, data, offset)
+		if err != nil {
+			return "", nil, offset, err
+		}
+		conds = append(conds, sql...)
+	}
+
+	qry := col + " " + operator + " (SELECT " + sel + " FROM " + rel.Table
+	if len(conds) > 0 {
+		qry += " WHERE " + strings.Join(conds, " AND ")
+	}
+	return qry + ")", args, offset, nil
+}
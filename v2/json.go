@@ -0,0 +1,150 @@
+package filterbuilder
+
+import "encoding/json"
+
+// A Filterer in JSON is an object with a single key naming its type, the same name as its Filter field:
+//
+//	{"eq": {"column": "status", "value": {"src": "NEW", "raw": true}}}
+//	{"not": {"group": {"and": [{"eq": ...}, {"gt": ...}]}}}
+
+// jsonFiltererKey gets the JSON key naming the type of a Filterer
+func jsonFiltererKey(f Filterer) (string, error) {
+	switch f.(type) {
+	case Eq:
+		return "eq", nil
+	case Ne:
+		return "ne", nil
+	case Lt:
+		return "lt", nil
+	case Lte:
+		return "lte", nil
+	case Gt:
+		return "gt", nil
+	case Gte:
+		return "gte", nil
+	case Lk:
+		return "lk", nil
+	case In:
+		return "in", nil
+	case Ni:
+		return "not_in", nil
+	case Bw:
+		return "between", nil
+	case Nb:
+		return "not_between", nil
+	case Range:
+		return "range", nil
+	case Group:
+		return "group", nil
+	case Or:
+		return "or", nil
+	case Not:
+		return "not", nil
+	case Exists:
+		return "exists", nil
+	case NotExists:
+		return "not_exists", nil
+	case TupleIn:
+		return "tuple_in", nil
+	case InQuery:
+		return "in_query", nil
+	case NotInQuery:
+		return "not_in_query", nil
+	}
+	return "", ErrFiltererNotSerializable
+}
+
+func marshalFilterer(f Filterer) ([]byte, error) {
+	key, err := jsonFiltererKey(f)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]Filterer{key: f})
+}
+
+func marshalFilterers(fs []Filterer) ([]json.RawMessage, error) {
+	raws := make([]json.RawMessage, 0, len(fs))
+	for _, f := range fs {
+		b, err := marshalFilterer(f)
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, b)
+	}
+	return raws, nil
+}
+
+func unmarshalFilterer(b []byte) (Filterer, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	if len(m) != 1 {
+		return nil, ErrFiltererNotSerializable
+	}
+	for key, raw := range m {
+		switch key {
+		case "eq":
+			return decodeFilterer[Eq](raw)
+		case "ne":
+			return decodeFilterer[Ne](raw)
+		case "lt":
+			return decodeFilterer[Lt](raw)
+		case "lte":
+			return decodeFilterer[Lte](raw)
+		case "gt":
+			return decodeFilterer[Gt](raw)
+		case "gte":
+			return decodeFilterer[Gte](raw)
+		case "lk":
+			return decodeFilterer[Lk](raw)
+		case "in":
+			return decodeFilterer[In](raw)
+		case "not_in":
+			return decodeFilterer[Ni](raw)
+		case "between":
+			return decodeFilterer[Bw](raw)
+		case "not_between":
+			return decodeFilterer[Nb](raw)
+		case "range":
+			return decodeFilterer[Range](raw)
+		case "group":
+			return decodeFilterer[Group](raw)
+		case "or":
+			return decodeFilterer[Or](raw)
+		case "not":
+			return decodeFilterer[Not](raw)
+		case "exists":
+			return decodeFilterer[Exists](raw)
+		case "not_exists":
+			return decodeFilterer[NotExists](raw)
+		case "tuple_in":
+			return decodeFilterer[TupleIn](raw)
+		case "in_query":
+			return decodeFilterer[InQuery](raw)
+		case "not_in_query":
+			return decodeFilterer[NotInQuery](raw)
+		}
+	}
+	return nil, ErrFiltererNotSerializable
+}
+
+func unmarshalFilterers(raws []json.RawMessage) ([]Filterer, error) {
+	fs := make([]Filterer, 0, len(raws))
+	for _, raw := range raws {
+		f, err := unmarshalFilterer(raw)
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+	return fs, nil
+}
+
+func decodeFilterer[T Filterer](raw json.RawMessage) (Filterer, error) {
+	var f T
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,215 @@
+package filterbuilder
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	ssd "github.com/shopspring/decimal"
+)
+
+// Interpolate renders the filter query with its arguments inlined as literals, for logs and support tickets.
+// The conditions are joined with AND. The result must not be run: use Build for statements.
+// The filter is built from a copy, so its Offset is left as it is.
+func (fb *Filter) Interpolate() (string, error) {
+	f := *fb
+	start := f.Offset
+	qry, args, err := f.Build()
+	if err != nil {
+		return "", err
+	}
+	lit, err := inlineArgs(qry, args, f.Placeholder, f.InSequence, start, f.Dialect)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lit, " AND "), nil
+}
+
+// BuildLiteral builds the filter query with its arguments inlined as escaped literals of the dialect,
+// for engines that cannot bind parameters. Prefer Build whenever parameters can be bound.
+// The filter is built from a copy, so its Offset is left as it is.
+func (fb *Filter) BuildLiteral() ([]string, error) {
+	f := *fb
+	start := f.Offset
+	qry, args, err := f.Build()
+	if err != nil {
+		return qry, err
+	}
+	return inlineArgs(qry, args, f.Placeholder, f.InSequence, start, f.Dialect)
+}
+
+// Interpolate renders a statement, such as the result of Weld, with its arguments inlined as literals of the dialect.
+// The placeholders are read with the Placeholder and InSequence of the dialect, numbered from 1.
+// The result is meant for logs and support tickets.
+func Interpolate(sql string, args []any, d Dialect) (string, error) {
+	lit, err := inlineArgs([]string{sql}, args, d.Placeholder, d.InSequence, 0, &d)
+	if err != nil {
+		return "", err
+	}
+	return lit[0], nil
+}
+
+// inlineArgs replaces the placeholders of the query fragments outside of string literals, quoted identifiers and comments
+// with the literals of their arguments, numbered after start. A placeholder without an argument fails,
+// so no placeholder is left in the result.
+func inlineArgs(qry []string, args []any, ph string, inSeq bool, start int, d *Dialect) ([]string, error) {
+	ph = strings.TrimSpace(ph)
+	numbered := inSeq && ph != "?"
+	next := 0
+	out := make([]string, 0, len(qry))
+	for _, q := range qry {
+		sb := strings.Builder{}
+		for i := 0; i < len(q); {
+			if j := skipQuoted(q, i, d); j > i {
+				sb.WriteString(q[i:j])
+				i = j
+				continue
+			}
+			if ph == "" || !strings.HasPrefix(q[i:], ph) {
+				sb.WriteByte(q[i])
+				i++
+				continue
+			}
+
+			j := i + len(ph)
+			k := next
+			if numbered {
+				for j < len(q) && q[j] >= '0' && q[j] <= '9' {
+					j++
+				}
+				// The placeholder prefix without a number, such as a $$ quote, is not a placeholder
+				n, err := strconv.Atoi(q[i+len(ph) : j])
+				if err != nil {
+					sb.WriteString(q[i:j])
+					i = j
+					continue
+				}
+				if n <= start || n-start > len(args) {
+					return out, ErrTooFewArguments
+				}
+				k = n - start - 1
+			} else {
+				if next >= len(args) {
+					return out, ErrTooFewArguments
+				}
+				next++
+			}
+
+			lit, err := literal(args[k], d)
+			if err != nil {
+				return out, err
+			}
+			sb.WriteString(lit)
+			i = j
+		}
+		out = append(out, sb.String())
+	}
+	return out, nil
+}
+
+// literal renders a value as an escaped SQL literal of the dialect
+func literal(v any, d *Dialect) (string, error) {
+	ld := d
+	if ld == nil {
+		ld = &Dialect{}
+	}
+
+	switch t := v.(type) {
+	case nil, Null:
+		return "NULL", nil
+	case string:
+		return ld.stringLiteral(t), nil
+	case []byte:
+		return ld.bytesLiteral(t), nil
+	case bool:
+		switch {
+		case d != nil && !d.BoolLiterals && t:
+			return "1", nil
+		case d != nil && !d.BoolLiterals:
+			return "0", nil
+		case t:
+			return "TRUE", nil
+		default:
+			return "FALSE", nil
+		}
+	case int:
+		return strconv.FormatInt(int64(t), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(t), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(t), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(t), 10), nil
+	case int64:
+		return strconv.FormatInt(t, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(t), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(t), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(t), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(t), 10), nil
+	case uint64:
+		return strconv.FormatUint(t, 10), nil
+	case float32:
+		return floatLiteral(float64(t), 32)
+	case float64:
+		return floatLiteral(t, 64)
+	case ssd.Decimal:
+		return t.String(), nil
+	case time.Time:
+		return ld.timeLiteral(t), nil
+	case []any:
+		lits := make([]string, 0, len(t))
+		for _, e := range t {
+			lit, err := literal(e, d)
+			if err != nil {
+				return "", err
+			}
+			lits = append(lits, lit)
+		}
+		return "ARRAY[" + strings.Join(lits, ",") + "]", nil
+	case driver.Valuer:
+		dv, err := t.Value()
+		if err != nil {
+			return "", err
+		}
+		return literal(dv, d)
+	}
+	return "", ErrLiteralType
+}
+
+func floatLiteral(f float64, bits int) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", ErrLiteralType
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits), nil
+}
+
+func (d *Dialect) stringLiteral(s string) string {
+	if d.BackslashEscapes {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return d.StringPrefix + "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (d *Dialect) bytesLiteral(b []byte) string {
+	format := d.BytesFormat
+	if format == "" {
+		format = "X'%s'"
+	}
+	return fmt.Sprintf(format, hex.EncodeToString(b))
+}
+
+func (d *Dialect) timeLiteral(t time.Time) string {
+	layout := d.TimeLayout
+	if layout == "" {
+		layout = "2006-01-02 15:04:05.999999999Z07:00"
+	}
+	return d.TimePrefix + "'" + t.Format(layout) + "'"
+}
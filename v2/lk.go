@@ -30,7 +30,11 @@ func LkDataPair(column string, value any) Lk {
 }
 
 func (f Lk) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildPair(f, data, "LIKE", ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Lk) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildPair(f, data, "LIKE", bs, offset)
 }
 
 func (f Lk) GetPair() any {
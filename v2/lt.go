@@ -1,8 +1,8 @@
 package filterbuilder
 
 type Lt struct {
-	Column string
-	Value  Value
+	Column string `json:"column,omitempty"` // Database table column
+	Value  Value  `json:"value,omitempty"`  // Struct field to get value or the value itself
 }
 
 func LtRawPair(column string, value any) Lt {
@@ -19,10 +19,23 @@ func LtDataPair(column string, field string) Lt {
 	}
 }
 
+// LtColPair simplifies column to column Lt pair.
+// Pairs compares the column with the other column instead of a value.
+func LtColPair(column string, other string) Lt {
+	return Lt{
+		Column: column,
+		Value:  Value{Src: other, Col: true},
+	}
+}
+
 func (f Lt) GetPair() any {
 	return f
 }
 
 func (f Lt) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildPair(f, data, "<", ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Lt) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildPair(f, data, "<", bs, offset)
 }
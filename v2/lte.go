@@ -1,8 +1,8 @@
 package filterbuilder
 
 type Lte struct {
-	Column string
-	Value  Value
+	Column string `json:"column,omitempty"` // Database table column
+	Value  Value  `json:"value,omitempty"`  // Struct field to get value or the value itself
 }
 
 func LteRawPair(column string, value any) Lte {
@@ -19,10 +19,23 @@ func LteDataPair(column string, field string) Lte {
 	}
 }
 
+// LteColPair simplifies column to column Lte pair.
+// Pairs compares the column with the other column instead of a value.
+func LteColPair(column string, other string) Lte {
+	return Lte{
+		Column: column,
+		Value:  Value{Src: other, Col: true},
+	}
+}
+
 func (f Lte) GetPair() any {
 	return f
 }
 
 func (f Lte) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildPair(f, data, "<=", ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Lte) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildPair(f, data, "<=", bs, offset)
 }
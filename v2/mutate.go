@@ -0,0 +1,357 @@
+package filterbuilder
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Set is an assignment of the SET clause of an UPDATE statement
+type Set struct {
+	Column string `json:"column,omitempty"` // Database table column
+	Value  Value  `json:"value,omitempty"`  // Struct field to get value or the value itself
+}
+
+// SetRawPair simplifies raw Set pair.
+// Pairs reads the value argument raw.
+func SetRawPair(column string, value any) Set {
+	return Set{
+		Column: column,
+		Value: Value{
+			Src: value,
+			Raw: true,
+		},
+	}
+}
+
+// SetDataPair simplifies data Set pair.
+// Pairs reads the value from Filter data field
+func SetDataPair(column string, value any) Set {
+	return Set{
+		Column: column,
+		Value: Value{
+			Src: value,
+		},
+	}
+}
+
+// BuildUpdate builds an UPDATE statement of table with the assignments and the filter.
+// The parameters of the assignments are numbered before those of the filter.
+// Assignments whose data field is unset are left out. Without a schema the assigned columns must be identifiers.
+//
+// The statement is refused when the filter builds to no condition or to one that is always true, such as
+// the 1=1 of a NOT IN over unset data fields, whatever AllowNoFilters is.
+// It is also refused when a column set by RequireColumns is not constrained.
+func (fb *Filter) BuildUpdate(table string, sets []Set) (string, []any, error) {
+	f := *fb
+	return f.buildUpdate(table, sets)
+}
+
+// buildUpdate builds an UPDATE statement. It is called on a copy of the filter, so the Offset of the filter is left as it is.
+func (fb *Filter) buildUpdate(table string, sets []Set) (string, []any, error) {
+	start := fb.Offset
+
+	fb.defaultPlaceholder()
+	bs := fb.newBuildState()
+
+	assigns := make([]string, 0, len(sets))
+	args := make([]any, 0, len(sets))
+	for _, s := range sets {
+		col, err := bs.schema.identifier(s.Column)
+		if err != nil {
+			return "", args, err
+		}
+		v, err := bs.value(fb.Data, s.Value)
+		if err == nil {
+			v, err = bs.arg(s.Column, v)
+		}
+		if err != nil {
+			return "", args, err
+		}
+		switch t := v.(type) {
+		case nil:
+			continue
+		case Null:
+			assigns = append(assigns, col+" = NULL")
+		case ColumnRef:
+			assigns = append(assigns, col+" = "+string(t))
+		default:
+			var ph string
+			ph, fb.Offset = bs.param(col, fb.Offset)
+			assigns = append(assigns, col+" = "+ph)
+			args = append(args, v)
+		}
+	}
+	if len(assigns) == 0 {
+		return "", args, ErrNoAssignments
+	}
+
+	where, wargs, err := fb.buildGuarded(bs)
+	if err != nil {
+		return "", args, err
+	}
+	args = append(args, wargs...)
+
+	sql := []string{"UPDATE " + table + " SET " + strings.Join(assigns, ", ") + " WHERE " + strings.Join(where, " AND ")}
+	sql, args = fb.dedup(bs, sql, args, start)
+	return sql[0], args, fb.checkParams(start + len(args))
+}
+
+// BuildDelete builds a DELETE statement of table with the filter.
+//
+// The statement is refused when the filter builds to no condition or to one that is always true, such as
+// the 1=1 of a NOT IN over unset data fields, whatever AllowNoFilters is.
+// It is also refused when a column set by RequireColumns is not constrained.
+func (fb *Filter) BuildDelete(table string) (string, []any, error) {
+	f := *fb
+	return f.buildDelete(table)
+}
+
+// buildDelete builds a DELETE statement. It is called on a copy of the filter, so the Offset of the filter is left as it is.
+func (fb *Filter) buildDelete(table string) (string, []any, error) {
+	start := fb.Offset
+
+	fb.defaultPlaceholder()
+	bs := fb.newBuildState()
+	where, args, err := fb.buildGuarded(bs)
+	if err != nil {
+		return "", args, err
+	}
+
+	sql := []string{"DELETE FROM " + table + " WHERE " + strings.Join(where, " AND ")}
+	sql, args = fb.dedup(bs, sql, args, start)
+	return sql[0], args, fb.checkParams(start + len(args))
+}
+
+// buildGuarded builds the filter of an UPDATE or DELETE statement.
+// It fails when the filter has no condition, has only conditions that are always true
+// or leaves a required column unconstrained.
+func (fb *Filter) buildGuarded(bs *buildState) ([]string, []any, error) {
+	where, args, err := fb.build(bs)
+	if err != nil {
+		return where, args, err
+	}
+	if !slices.ContainsFunc(where, func(cond string) bool { return !alwaysTrue(cond, bs.dialect) }) {
+		return where, args, ErrNoFilterSet
+	}
+	for _, req := range fb.Required {
+		ok, err := fb.constrains(bs, req)
+		if err != nil {
+			return where, args, err
+		}
+		if !ok {
+			return where, args, ErrRequiredColumn
+		}
+	}
+	return where, args, nil
+}
+
+// constrains tells if a top level filter on column builds to a condition.
+// Or, negated filters and filters without a column of the rows are not counted since they do not pin the rows.
+func (fb *Filter) constrains(bs *buildState, column string) (bool, error) {
+	for _, f := range fb.terms() {
+		var cols []string
+		switch t := f.(type) {
+		case Or, Not, Ni, Nb, Ne, NotExists, NotInQuery, Exists, Seek, Expr:
+			continue
+		case TupleIn:
+			cols = t.Columns
+		default:
+			v := reflect.ValueOf(f.GetPair())
+			if v.Kind() != reflect.Struct {
+				continue
+			}
+			fv := v.FieldByName("Column")
+			if !fv.IsValid() || fv.Kind() != reflect.String {
+				continue
+			}
+			cols = []string{fv.String()}
+		}
+
+		found := false
+		for _, c := range cols {
+			if strings.EqualFold(c, column) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		str, _, _, err := buildFilterer(f, bs, fb.Data, 0)
+		if err != nil {
+			return false, err
+		}
+		if str != "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// truth is the value of a condition folded from its constant terms
+type truth int
+
+const (
+	truthUnknown truth = iota // Depends on the row
+	truthTrue
+	truthFalse
+)
+
+// alwaysTrue tells if a condition is true whatever the row, such as the 1=1 or NOT (1=0) of filters over unset data.
+// The condition is folded from its 1=1 and 1=0 terms through AND, OR and NOT. Anything else depends on the row.
+func alwaysTrue(cond string, d *Dialect) bool {
+	p := condParser{toks: condTokens(cond, d)}
+	return p.or() == truthTrue && p.i == len(p.toks)
+}
+
+// condTokens splits a condition into parentheses, the AND, OR and NOT keywords and the terms between them.
+// Parentheses following a term, such as those of IN (...) or a function call, are a part of the term.
+func condTokens(cond string, d *Dialect) []string {
+	var (
+		toks []string
+		term strings.Builder
+	)
+	flush := func() {
+		if t := strings.TrimSpace(term.String()); t != "" {
+			toks = append(toks, t)
+		}
+		term.Reset()
+	}
+	for i := 0; i < len(cond); {
+		if j := skipQuoted(cond, i, d); j > i {
+			term.WriteString(cond[i:j])
+			i = j
+			continue
+		}
+		c := cond[i]
+		switch {
+		case c == '(' && strings.TrimSpace(term.String()) != "":
+			// The parentheses belong to the term, up to the matching one
+			depth := 0
+			j := i
+			for ; j < len(cond); j++ {
+				if cond[j] == '(' {
+					depth++
+				} else if cond[j] == ')' {
+					depth--
+					if depth == 0 {
+						j++
+						break
+					}
+				}
+			}
+			term.WriteString(cond[i:j])
+			i = j
+			continue
+		case c == '(' || c == ')':
+			flush()
+			toks = append(toks, string(c))
+		case isIdentByte(c) && (i == 0 || !isIdentByte(cond[i-1])):
+			j := i
+			for j < len(cond) && isIdentByte(cond[j]) {
+				j++
+			}
+			switch word := strings.ToUpper(cond[i:j]); word {
+			case "AND", "OR", "NOT":
+				flush()
+				toks = append(toks, word)
+			default:
+				term.WriteString(cond[i:j])
+			}
+			i = j
+			continue
+		default:
+			term.WriteByte(c)
+		}
+		i++
+	}
+	flush()
+	return toks
+}
+
+// condParser folds the tokens of a condition
+type condParser struct {
+	toks []string
+	i    int
+}
+
+func (p *condParser) peek() string {
+	if p.i < len(p.toks) {
+		return p.toks[p.i]
+	}
+	return ""
+}
+
+func (p *condParser) or() truth {
+	v := p.and()
+	for p.peek() == "OR" {
+		p.i++
+		r := p.and()
+		switch {
+		case v == truthTrue || r == truthTrue:
+			v = truthTrue
+		case v == truthFalse && r == truthFalse:
+			v = truthFalse
+		default:
+			v = truthUnknown
+		}
+	}
+	return v
+}
+
+func (p *condParser) and() truth {
+	v := p.not()
+	for p.peek() == "AND" {
+		p.i++
+		r := p.not()
+		switch {
+		case v == truthFalse || r == truthFalse:
+			v = truthFalse
+		case v == truthTrue && r == truthTrue:
+			v = truthTrue
+		default:
+			v = truthUnknown
+		}
+	}
+	return v
+}
+
+func (p *condParser) not() truth {
+	if p.peek() != "NOT" {
+		return p.term()
+	}
+	p.i++
+	switch p.not() {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	}
+	return truthUnknown
+}
+
+func (p *condParser) term() truth {
+	switch t := p.peek(); t {
+	case "(":
+		p.i++
+		v := p.or()
+		if p.peek() != ")" {
+			return truthUnknown
+		}
+		p.i++
+		return v
+	case "", ")", "AND", "OR":
+		return truthUnknown
+	default:
+		p.i++
+		switch strings.ReplaceAll(t, " ", "") {
+		case "1=1":
+			return truthTrue
+		case "1=0":
+			return truthFalse
+		}
+		return truthUnknown
+	}
+}
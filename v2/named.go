@@ -0,0 +1,82 @@
+package filterbuilder
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// paramNames names the parameters of a build in named mode.
+// It is shared by the nested filters of the build so the names never collide.
+type paramNames struct {
+	count map[string]int
+	names []string
+}
+
+func newParamNames() *paramNames {
+	return &paramNames{
+		count: map[string]int{},
+		names: make([]string, 0, 10),
+	}
+}
+
+// next gets the next free name for a column, such as first_name_2 for the second parameter of u.first_name
+func (pn *paramNames) next(column string) string {
+	base := paramBase(column)
+	pn.count[base]++
+	name := base + "_" + strconv.Itoa(pn.count[base])
+	pn.names = append(pn.names, name)
+	return name
+}
+
+// paramBase gets the base name of a parameter from a column, without its qualifier
+// and with the characters that cannot be in a name replaced with underscores
+func paramBase(column string) string {
+	if i := strings.LastIndexByte(column, '.'); i >= 0 {
+		column = column[i+1:]
+	}
+	b := []byte(strings.ToLower(column))
+	for i, c := range b {
+		if !(c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z') {
+			b[i] = '_'
+		}
+	}
+	base := strings.Trim(string(b), "_")
+	if base == "" || base[0] >= '0' && base[0] <= '9' {
+		base = "p" + base
+	}
+	return base
+}
+
+// BuildNamed builds the filter query with named parameters such as :first_name_1, and returns the arguments as sql.NamedArg.
+// The parameters are prefixed with @ when the Placeholder of the filter starts with @, and with : otherwise.
+// Parameters of the same column are numbered in the order they appear, so the names never collide.
+func (fb *Filter) BuildNamed() ([]string, []sql.NamedArg, error) {
+	start := fb.Offset
+
+	fb.defaultPlaceholder()
+	if fb.empty() && !fb.AllowNoFilters {
+		return []string{}, []sql.NamedArg{}, ErrNoFilterSet
+	}
+
+	bs := fb.newBuildState()
+	bs.ph = ":"
+	if strings.HasPrefix(fb.Placeholder, "@") {
+		bs.ph = "@"
+	}
+	bs.names = newParamNames()
+
+	qry, args, err := fb.build(bs)
+	if err != nil {
+		return qry, nil, err
+	}
+	if len(args) != len(bs.names.names) {
+		return qry, nil, ErrNamedArgMismatch
+	}
+
+	named := make([]sql.NamedArg, 0, len(args))
+	for i, a := range args {
+		named = append(named, sql.Named(bs.names.names[i], a))
+	}
+	return qry, named, fb.checkParams(start + len(named))
+}
@@ -0,0 +1,50 @@
+package filterbuilder
+
+// Nb is NOT BETWEEN in SQL filter
+type Nb struct {
+	Column string  `json:"column,omitempty"` // Database table column
+	Value  []Value `json:"value,omitempty"`  // Struct field to get value
+}
+
+// NbRawPair simplifies raw Nb pair.
+// Pairs reads the value argument raw.
+func NbRawPair(column string, value ...any) Nb {
+	values := make([]Value, 0, len(value))
+	for _, v := range value {
+		values = append(values, Value{
+			Src: v,
+			Raw: true,
+		})
+	}
+	return Nb{
+		Column: column,
+		Value:  values,
+	}
+}
+
+// NbDataPair simplifies Nb data multi-pair.
+// Pairs reads the Data field values via fieldName argument.
+func NbDataPair(column string, fieldName ...string) Nb {
+	v := make([]Value, 0, len(fieldName))
+	for _, a := range fieldName {
+		v = append(v, Value{
+			Src: a,
+		})
+	}
+	return Nb{
+		Column: column,
+		Value:  v,
+	}
+}
+
+func (f Nb) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Nb) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildRangePair(f, data, "NOT BETWEEN", bs, offset)
+}
+
+func (f Nb) GetPair() any {
+	return f
+}
@@ -29,8 +29,24 @@ func NeDataPair(column string, value any) Ne {
 	}
 }
 
+// NeColPair simplifies column to column Ne pair.
+// Pairs compares the column with the other column instead of a value.
+func NeColPair(column string, other string) Ne {
+	return Ne{
+		Column: column,
+		Value: Value{
+			Src: other,
+			Col: true,
+		},
+	}
+}
+
 func (f Ne) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildPair(f, data, "<>", ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Ne) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildPair(f, data, "<>", bs, offset)
 }
 
 func (f Ne) GetPair() any {
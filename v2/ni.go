@@ -38,7 +38,11 @@ func NiDataPair(column string, fieldName ...string) Ni {
 }
 
 func (f Ni) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
-	return buildMembershipPair(f, data, "NOT IN", ph, inSeq, offset)
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Ni) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	return buildMembershipPair(f, data, "NOT IN", bs, offset)
 }
 
 func (f Ni) GetPair() any {
@@ -0,0 +1,124 @@
+package filterbuilder
+
+// Not is the NOT expression in SQL. It negates any Filterer.
+//
+// When the Filter pushes down negations, the negation is applied to the wrapped filter instead,
+// so Not{Eq} renders <> and Not{In} renders NOT IN. A Group or an Or is negated by De Morgan's laws.
+type Not struct {
+	Filter Filterer
+}
+
+func (n Not) GetPair() any {
+	return n
+}
+
+func (n Not) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return n.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (n Not) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	if n.Filter == nil {
+		return "", nil, offset, nil
+	}
+
+	// Dropping an always true term under a negation would turn it to true instead of false
+	bs = bs.andOnly()
+	if bs.pushDownNot && !bs.comparesNull(n.Filter, data) {
+		if f, ok := negate(n.Filter); ok {
+			return buildFilterer(f, bs, data, offset)
+		}
+	}
+	str, rv, offset, err := buildFilterer(n.Filter, bs, data, offset)
+	if err != nil || str == "" {
+		return str, rv, offset, err
+	}
+	switch n.Filter.(type) {
+	case Group, Or:
+		return "NOT " + str, rv, offset, nil
+	}
+	return "NOT (" + str + ")", rv, offset, nil
+}
+
+func (n Not) MarshalJSON() ([]byte, error) {
+	return marshalFilterer(n.Filter)
+}
+
+func (n *Not) UnmarshalJSON(b []byte) error {
+	f, err := unmarshalFilterer(b)
+	if err != nil {
+		return err
+	}
+	n.Filter = f
+	return nil
+}
+
+// comparesNull tells if f is a <, <=, > or >= pair with a Null value.
+// Such a pair renders IS NULL whatever its operator, so it is not negated by swapping the operator.
+func (bs *buildState) comparesNull(f Filterer, data any) bool {
+	var val Value
+	switch t := f.(type) {
+	case Lt:
+		val = t.Value
+	case Lte:
+		val = t.Value
+	case Gt:
+		val = t.Value
+	case Gte:
+		val = t.Value
+	default:
+		return false
+	}
+	v, err := bs.value(data, val)
+	_, null := v.(Null)
+	return err == nil && null
+}
+
+// negate returns the filter that is the negation of f.
+// It returns false when f has no negated counterpart.
+func negate(f Filterer) (Filterer, bool) {
+	switch t := f.(type) {
+	case Eq:
+		return Ne(t), true
+	case Ne:
+		return Eq(t), true
+	case Lt:
+		return Gte(t), true
+	case Lte:
+		return Gt(t), true
+	case Gt:
+		return Lte(t), true
+	case Gte:
+		return Lt(t), true
+	case In:
+		return Ni(t), true
+	case Ni:
+		return In(t), true
+	case Bw:
+		return Nb(t), true
+	case Nb:
+		return Bw(t), true
+	case Exists:
+		return NotExists(t), true
+	case NotExists:
+		return Exists(t), true
+	case InQuery:
+		return NotInQuery(t), true
+	case NotInQuery:
+		return InQuery(t), true
+	case Not:
+		return t.Filter, t.Filter != nil
+	case Group:
+		pair := make([]Filterer, 0, len(t.And))
+		for _, a := range t.And {
+			pair = append(pair, Not{Filter: a})
+		}
+		return Or{Pair: pair}, true
+	case Or:
+		and := make([]Filterer, 0, len(t.Pair))
+		for _, p := range t.Pair {
+			and = append(and, Not{Filter: p})
+		}
+		return Group{And: and}, true
+	}
+	return nil, false
+}
@@ -1,6 +1,75 @@
 package filterbuilder
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 // Or is the OR expression in SQL
 type Or struct {
 	Pair []Filterer
 }
+
+func (o Or) GetPair() any {
+	return o
+}
+
+func (o Or) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return o.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (o Or) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	parts := []string{}
+	args := []any{}
+
+	// A dropped term is only always true in AND context, so empty NOT IN render 1=1 in an OR
+	bs = bs.andOnly()
+
+	for _, f := range o.Pair {
+		str, rv, newOffset, err := buildFilterer(f, bs, data, offset)
+		if err != nil {
+			return "", nil, offset, err
+		}
+		offset = newOffset
+
+		if str != "" {
+			parts = append(parts, str)
+		}
+
+		if isSliceType(rv) {
+			args = append(args, rv.([]any)...)
+		} else if rv != nil {
+			args = append(args, rv)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", args, offset, nil
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args, offset, nil
+}
+
+func (o Or) MarshalJSON() ([]byte, error) {
+	pair, err := marshalFilterers(o.Pair)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Pair []json.RawMessage `json:"pair"`
+	}{pair})
+}
+
+func (o *Or) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Pair []json.RawMessage `json:"pair"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	pair, err := unmarshalFilterers(v.Pair)
+	if err != nil {
+		return err
+	}
+	o.Pair = pair
+	return nil
+}
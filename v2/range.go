@@ -0,0 +1,147 @@
+package filterbuilder
+
+// Range is a range filter in SQL with optional bounds.
+// A bound that is not set, unset in the Data or NULL leaves the range open on that side.
+type Range struct {
+	Column        string `json:"column,omitempty"`         // Database table column
+	From          *Value `json:"from,omitempty"`           // Lower bound
+	To            *Value `json:"to,omitempty"`             // Upper bound
+	FromExclusive bool   `json:"from_exclusive,omitempty"` // Lower bound is excluded from the range
+	ToExclusive   bool   `json:"to_exclusive,omitempty"`   // Upper bound is excluded from the range
+}
+
+// RangeRawPair simplifies raw Range pair.
+// A nil bound leaves the range open on that side.
+func RangeRawPair(column string, from, to any) Range {
+	r := Range{Column: column}
+	if from != nil {
+		r.From = &Value{Src: from, Raw: true}
+	}
+	if to != nil {
+		r.To = &Value{Src: to, Raw: true}
+	}
+	return r
+}
+
+// RangeRelPair simplifies a Range between relative time expressions such as "now-7d" or "startOfWeek".
+// An empty expression leaves the range open on that side.
+func RangeRelPair(column string, from, to string) Range {
+	r := Range{Column: column}
+	if from != "" {
+		r.From = &Value{Src: from, Rel: true}
+	}
+	if to != "" {
+		r.To = &Value{Src: to, Rel: true}
+	}
+	return r
+}
+
+// RangeDataPair simplifies data Range pair.
+// Pairs reads the Data field values via the field name arguments. An empty field name leaves the range open on that side.
+func RangeDataPair(column string, fromField, toField string) Range {
+	r := Range{Column: column}
+	if fromField != "" {
+		r.From = &Value{Src: fromField}
+	}
+	if toField != "" {
+		r.To = &Value{Src: toField}
+	}
+	return r
+}
+
+// Exclusive sets whether the bounds are excluded from the range
+func (f Range) Exclusive(from, to bool) Range {
+	f.FromExclusive = from
+	f.ToExclusive = to
+	return f
+}
+
+// Values returns the bounds of the range. A bound that is not set is left out.
+func (f Range) Values() []Value {
+	vals := make([]Value, 0, 2)
+	if f.From != nil {
+		vals = append(vals, *f.From)
+	}
+	if f.To != nil {
+		vals = append(vals, *f.To)
+	}
+	return vals
+}
+
+func (f Range) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Range) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	col, err := bs.column(f.Column)
+	if err != nil {
+		return "", nil, offset, err
+	}
+	from, err := rangeBound(bs, data, f.From)
+	if err != nil {
+		return "", nil, offset, err
+	}
+	to, err := rangeBound(bs, data, f.To)
+	if err != nil {
+		return "", nil, offset, err
+	}
+	if from, err = bs.arg(f.Column, from); err != nil {
+		return "", nil, offset, err
+	}
+	if to, err = bs.arg(f.Column, to); err != nil {
+		return "", nil, offset, err
+	}
+
+	var fromPh, toPh string
+	args := make([]any, 0, 2)
+	if from != nil {
+		fromPh, offset = bs.param(col, offset)
+		args = append(args, from)
+	}
+	if to != nil {
+		toPh, offset = bs.param(col, offset)
+		args = append(args, to)
+	}
+
+	fromOp, toOp := " >= ", " <= "
+	if f.FromExclusive {
+		fromOp = " > "
+	}
+	if f.ToExclusive {
+		toOp = " < "
+	}
+
+	switch {
+	case from == nil && to == nil:
+		return "", args, offset, nil
+	case to == nil:
+		return col + fromOp + fromPh, args, offset, nil
+	case from == nil:
+		return col + toOp + toPh, args, offset, nil
+	case !f.FromExclusive && !f.ToExclusive:
+		return col + " BETWEEN " + fromPh + " AND " + toPh, args, offset, nil
+	}
+	return "(" + col + fromOp + fromPh + " AND " + col + toOp + toPh + ")", args, offset, nil
+}
+
+func (f Range) GetPair() any {
+	return f
+}
+
+// rangeBound gets the value of a bound. Nil is returned for an open bound.
+func rangeBound(bs *buildState, data any, p *Value) (any, error) {
+	if p == nil {
+		return nil, nil
+	}
+	v, err := bs.value(data, *p)
+	if err != nil {
+		return nil, err
+	}
+	switch v.(type) {
+	case Null:
+		return nil, nil
+	case ColumnRef:
+		return nil, ErrColumnRefNotSupported
+	}
+	return v, nil
+}
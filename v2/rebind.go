@@ -0,0 +1,35 @@
+package filterbuilder
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rebind converts the placeholders of a statement from the style of one dialect to the style of another,
+// skipping string literals, quoted identifiers and comments.
+// Numbered placeholders of the result are shifted by offset. A doubled ?? in a ? statement is an escaped ?.
+// Numbered placeholders can only become positional when they appear in order from 1, each once.
+func Rebind(sql string, from, to Dialect, offset int) (string, error) {
+	fph, tph := strings.TrimSpace(from.Placeholder), strings.TrimSpace(to.Placeholder)
+	fnum := from.InSequence && fph != "?"
+	tnum := to.InSequence && tph != "?"
+	if fph == tph && fnum == tnum && (!tnum || offset == 0) {
+		return sql, nil
+	}
+
+	k := 0
+	return rebindMarkers(sql, fph, fnum, &from, func(n int) (string, error) {
+		k++
+		switch {
+		case !tnum:
+			if fnum && n != k {
+				return "", ErrRebindOrder
+			}
+			return tph, nil
+		case fnum:
+			return tph + strconv.Itoa(n+offset), nil
+		default:
+			return tph + strconv.Itoa(k+offset), nil
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package filterbuilder
+
+import (
+	"strconv"
+	"strings"
+)
+
+// skipQuoted returns the index after the string literal, quoted identifier or comment starting at i,
+// or i when there is none.
+// It knows 'string', "identifier", `identifier`, -- line comments and /* block comments */,
+// and [identifier] when brackets quote identifiers in the dialect.
+// When backslashes are escapes in the dialect, a backslash in a quoted string escapes the character after it.
+func skipQuoted(sql string, i int, d *Dialect) int {
+	switch c := sql[i]; {
+	case c == '\'' || c == '"' || c == '`':
+		// A doubled quote is an escaped quote and is skipped as two adjacent literals
+		backslashes := c != '`' && d.backslashEscapes()
+		for j := i + 1; j < len(sql); j++ {
+			switch {
+			case sql[j] == '\\' && backslashes:
+				j++
+			case sql[j] == c:
+				return j + 1
+			}
+		}
+		return len(sql)
+	case c == '[' && d.bracketQuotes():
+		if j := strings.IndexByte(sql[i+1:], ']'); j >= 0 {
+			return i + j + 2
+		}
+		return len(sql)
+	case c == '-' && strings.HasPrefix(sql[i:], "--"):
+		if j := strings.IndexByte(sql[i:], '\n'); j >= 0 {
+			return i + j + 1
+		}
+		return len(sql)
+	case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+		if j := strings.Index(sql[i+2:], "*/"); j >= 0 {
+			return i + j + 4
+		}
+		return len(sql)
+	}
+	return i
+}
+
+// replaceMarkers replaces every ? marker outside of string literals, quoted identifiers and comments
+// with the result of fn. A doubled ?? is an escaped ? and is written as a single ?.
+func replaceMarkers(sql string, d *Dialect, fn func() string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(sql); {
+		if j := skipQuoted(sql, i, d); j > i {
+			sb.WriteString(sql[i:j])
+			i = j
+			continue
+		}
+		if sql[i] != '?' {
+			sb.WriteByte(sql[i])
+			i++
+			continue
+		}
+		if strings.HasPrefix(sql[i:], "??") {
+			sb.WriteByte('?')
+			i += 2
+			continue
+		}
+		sb.WriteString(fn())
+		i++
+	}
+	return sb.String()
+}
+
+// weldMarker marks where Weld places the filter in a statement
+const weldMarker = "/*filter*/"
+
+// weldPoint is where a filter is welded into a statement
+type weldPoint struct {
+	where  int // Index of the top level WHERE keyword, or -1 when there is none
+	cond   int // Index after the top level WHERE keyword
+	end    int // Index of the first top level clause that follows the WHERE clause, or the length of the statement
+	marker int // Index of the first weld marker, or -1 when there is none
+	setOp  int // Index of the first top level UNION, INTERSECT, EXCEPT or MINUS, or -1 when there is none
+}
+
+// findWeldPoint scans a statement for its top level WHERE clause, the clauses following it, the set operators
+// and the weld marker. Keywords in string literals, quoted identifiers, comments and parentheses are ignored.
+func findWeldPoint(sql string, d *Dialect) weldPoint {
+	wp := weldPoint{where: -1, end: len(sql), marker: -1, setOp: -1}
+	ended := false
+	depth := 0
+	for i := 0; i < len(sql); {
+		if strings.HasPrefix(sql[i:], weldMarker) {
+			if wp.marker < 0 {
+				wp.marker = i
+			}
+			i += len(weldMarker)
+			continue
+		}
+		if j := skipQuoted(sql, i, d); j > i {
+			i = j
+			continue
+		}
+		switch c := sql[i]; {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case isIdentByte(c) && (i == 0 || !isIdentByte(sql[i-1])):
+			j := i
+			for j < len(sql) && isIdentByte(sql[j]) {
+				j++
+			}
+			if depth == 0 {
+				word := strings.ToUpper(sql[i:j])
+				switch {
+				case isSetOperator(word):
+					if wp.setOp < 0 {
+						wp.setOp = i
+					}
+					if !ended {
+						wp.end, ended = i, true
+					}
+				case ended:
+					// Only set operators are looked for after the clauses that follow the WHERE clause
+				case word == "WHERE" && wp.where < 0:
+					wp.where, wp.cond = i, j
+				case isTrailingClause(word, sql[j:]):
+					wp.end, ended = i, true
+				}
+			}
+			i = j
+			continue
+		}
+		i++
+	}
+	return wp
+}
+
+// isTrailingClause tells if word, followed by rest, starts a clause that comes after the WHERE clause
+func isTrailingClause(word, rest string) bool {
+	switch word {
+	case "HAVING", "LIMIT", "OFFSET", "FETCH", "WINDOW", "RETURNING":
+		return true
+	case "GROUP", "ORDER":
+		return nextWord(rest) == "BY"
+	case "FOR":
+		next := nextWord(rest)
+		return next == "UPDATE" || next == "SHARE"
+	}
+	return false
+}
+
+// isSetOperator tells if word combines the results of two queries
+func isSetOperator(word string) bool {
+	switch word {
+	case "UNION", "INTERSECT", "EXCEPT", "MINUS":
+		return true
+	}
+	return false
+}
+
+// nextWord gets the word at the start of s in upper case, after any white space
+func nextWord(s string) string {
+	s = strings.TrimLeft(s, " \t\r\n")
+	i := 0
+	for i < len(s) && isIdentByte(s[i]) {
+		i++
+	}
+	return strings.ToUpper(s[:i])
+}
+
+// isIdentByte tells if c can be a part of an identifier. A dot is included so qualified names are one word.
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '.' || c == '$' || c == '#' || c == '@' ||
+		c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// countMarkers counts the placeholders ph outside of string literals, quoted identifiers and comments
+func countMarkers(sql, ph string, d *Dialect) int {
+	n := 0
+	for i := 0; i < len(sql); {
+		if j := skipQuoted(sql, i, d); j > i {
+			i = j
+			continue
+		}
+		if strings.HasPrefix(sql[i:], ph) {
+			n++
+			i += len(ph)
+			continue
+		}
+		i++
+	}
+	return n
+}
+
+// lineEnd returns a new line when sql ends in a -- line comment, so more SQL can follow it
+func lineEnd(sql string, d *Dialect) string {
+	for i := 0; i < len(sql); {
+		j := skipQuoted(sql, i, d)
+		if j == len(sql) && strings.HasPrefix(sql[i:], "--") && !strings.HasSuffix(sql, "\n") {
+			return "\n"
+		}
+		if j > i {
+			i = j
+			continue
+		}
+		i++
+	}
+	return ""
+}
+
+// rebindMarkers replaces every placeholder ph outside of string literals, quoted identifiers and comments
+// with the result of fn. Numbered placeholders pass their number to fn, others pass zero.
+// A number that cannot be read leaves the placeholder as it is.
+func rebindMarkers(sql, ph string, numbered bool, d *Dialect, fn func(n int) (string, error)) (string, error) {
+	if ph == "?" && !numbered {
+		var err error
+		out := replaceMarkers(sql, d, func() string {
+			s, e := fn(0)
+			if e != nil && err == nil {
+				err = e
+			}
+			return s
+		})
+		return out, err
+	}
+
+	sb := strings.Builder{}
+	for i := 0; i < len(sql); {
+		if j := skipQuoted(sql, i, d); j > i {
+			sb.WriteString(sql[i:j])
+			i = j
+			continue
+		}
+		if ph == "" || !strings.HasPrefix(sql[i:], ph) {
+			sb.WriteByte(sql[i])
+			i++
+			continue
+		}
+		j := i + len(ph)
+		n := 0
+		if numbered {
+			for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+				j++
+			}
+			var err error
+			if n, err = strconv.Atoi(sql[i+len(ph) : j]); err != nil {
+				sb.WriteString(sql[i:j])
+				i = j
+				continue
+			}
+		}
+		s, err := fn(n)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(s)
+		i = j
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,69 @@
+package filterbuilder
+
+import "strings"
+
+// Column describes a column that a filter can reference
+type Column struct {
+	Name    string       // SQL expression of the column. Defaults to its key in the Schema.
+	Convert ArgConverter // Converts the arguments compared with the column
+	Type    ColumnType   // Type that the values compared with the column are coerced to
+	Values  []string     // Allowed values of a TypeEnum column
+}
+
+// Schema is a registry of the columns a filter can reference, keyed by their public names.
+// When set on a Filter, referencing a column that is not in the Schema fails the build.
+type Schema map[string]Column
+
+// lookup finds a column by its public name, ignoring case
+func (s Schema) lookup(name string) (string, Column, bool) {
+	if c, ok := s[name]; ok {
+		return name, c, true
+	}
+	for k, c := range s {
+		if strings.EqualFold(k, name) {
+			return k, c, true
+		}
+	}
+	return "", Column{}, false
+}
+
+// column gets the SQL expression of a column by its public name
+func (s Schema) column(name string) (string, error) {
+	if s == nil {
+		return name, nil
+	}
+	k, c, ok := s.lookup(name)
+	if !ok {
+		return "", ErrColumnNotAllowed
+	}
+	if c.Name == "" {
+		return k, nil
+	}
+	return c.Name, nil
+}
+
+// identifier gets the SQL expression of a column that is inlined in a clause other than a condition,
+// such as the select list of a subquery. Without a schema the name must be a plain identifier
+// or one qualified with dots, such as o.created_at.
+func (s Schema) identifier(name string) (string, error) {
+	if s == nil && !isIdentifier(name) {
+		return "", ErrInvalidIdentifier
+	}
+	return s.column(name)
+}
+
+// isIdentifier tells if name is a plain identifier or one qualified with dots
+func isIdentifier(name string) bool {
+	for _, part := range strings.Split(name, ".") {
+		if part == "" || (part[0] >= '0' && part[0] <= '9') {
+			return false
+		}
+		for i := 0; i < len(part); i++ {
+			c := part[i]
+			if c != '_' && (c < '0' || c > '9') && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package filterbuilder
+
+import "strings"
+
+// SeekKey is a column of the sort order that a keyset page is sought by
+type SeekKey struct {
+	Column string `json:"column,omitempty"` // Database table column
+	Desc   bool   `json:"desc,omitempty"`   // Column is sorted in descending order
+}
+
+// Seek is the keyset (seek) pagination predicate in SQL.
+// It matches the rows that come after the row with the Value keys in the sort order of the Keys.
+// The last key should be unique, such as the primary key, so that no row is skipped.
+//
+// When all keys sort in the same direction and the dialect compares row values, it renders (a, b) > (?, ?).
+// Otherwise it renders the expanded form (a > ? OR (a = ? AND b > ?)).
+type Seek struct {
+	Keys  []SeekKey `json:"keys,omitempty"`  // Columns of the sort order
+	Value []Value   `json:"value,omitempty"` // Key values of the last row of the previous page, one per key
+}
+
+// SeekAfter creates a Seek past the row with the raw key values
+func SeekAfter(keys []SeekKey, values ...any) Seek {
+	v := make([]Value, 0, len(values))
+	for _, a := range values {
+		v = append(v, Value{
+			Src: a,
+			Raw: true,
+		})
+	}
+	return Seek{
+		Keys:  keys,
+		Value: v,
+	}
+}
+
+func (f Seek) GetPair() any {
+	return f
+}
+
+func (f Seek) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f Seek) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	if len(f.Keys) == 0 || len(f.Keys) != len(f.Value) {
+		return "", nil, offset, ErrSeekSize
+	}
+
+	cols := make([]string, 0, len(f.Keys))
+	for _, k := range f.Keys {
+		col, err := bs.column(k.Column)
+		if err != nil {
+			return "", nil, offset, err
+		}
+		cols = append(cols, col)
+	}
+	vals, err := bs.values(data, f.Value)
+	if err != nil {
+		return "", nil, offset, err
+	}
+	for _, v := range vals {
+		switch v.(type) {
+		case nil, Null:
+			return "", nil, offset, ErrSeekNullKey
+		case ColumnRef:
+			return "", nil, offset, ErrColumnRefNotSupported
+		}
+	}
+	names := make([]string, 0, len(f.Keys))
+	for _, k := range f.Keys {
+		names = append(names, k.Column)
+	}
+	if err := bs.rowArgs(names, vals); err != nil {
+		return "", nil, offset, err
+	}
+
+	var ph string
+	sameDir := true
+	for _, k := range f.Keys {
+		sameDir = sameDir && k.Desc == f.Keys[0].Desc
+	}
+	if len(cols) == 1 || (sameDir && bs.rowCompare()) {
+		ph, offset = bs.rowParams(cols, offset)
+		op := seekOperator(f.Keys[0])
+		if len(cols) == 1 {
+			return cols[0] + op + ph, vals, offset, nil
+		}
+		return "(" + strings.Join(cols, ", ") + ")" + op + "(" + ph + ")", vals, offset, nil
+	}
+
+	// Expanded form: a row comes after when it is past the first key that differs
+	args := make([]any, 0, len(vals)*(len(vals)+1)/2)
+	parts := make([]string, 0, len(cols))
+	for i := range cols {
+		conds := make([]string, 0, i+1)
+		for j := range i {
+			ph, offset = bs.param(cols[j], offset)
+			conds = append(conds, cols[j]+" = "+ph)
+			args = append(args, vals[j])
+		}
+		ph, offset = bs.param(cols[i], offset)
+		conds = append(conds, cols[i]+seekOperator(f.Keys[i])+ph)
+		args = append(args, vals[i])
+		if len(conds) == 1 {
+			parts = append(parts, conds[0])
+			continue
+		}
+		parts = append(parts, "("+strings.Join(conds, " AND ")+")")
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args, offset, nil
+}
+
+func seekOperator(k SeekKey) string {
+	if k.Desc {
+		return " < "
+	}
+	return " > "
+}
@@ -0,0 +1,131 @@
+package filterbuilder
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Paging selects how a dialect limits the rows of a SELECT statement
+type Paging int
+
+const (
+	PagingLimitOffset Paging = iota // LIMIT n OFFSET m
+	PagingOffsetFetch               // OFFSET m ROWS FETCH NEXT n ROWS ONLY
+	PagingTop                       // SELECT TOP n. Offsets are not supported.
+)
+
+// Select is a SELECT statement around a Filter.
+// The dialect and the sort order of the statement are taken from the Filter.
+type Select struct {
+	Columns []string // Selected columns. When empty, all columns are selected.
+	From    string   // Table with its alias, e.g. "orders o"
+	Joins   []string // Joined tables, e.g. "JOIN customers c ON c.id = o.customer_id"
+	GroupBy []string // Grouped columns
+	Filter  *Filter  // Filter of the WHERE, HAVING and ORDER BY clauses
+	Limit   int      // Maximum number of rows. Zero means no limit.
+	Offset  int      // Number of rows to skip
+}
+
+// Build builds the SELECT statement and its arguments
+func (s *Select) Build() (string, []any, error) {
+	c, err := s.clauses()
+	if err != nil {
+		return "", c.Args, err
+	}
+
+	var (
+		d     *Dialect
+		order []string
+	)
+	if s.Filter != nil {
+		d = s.Filter.Dialect
+		if order, err = s.Filter.BuildSort(); err != nil {
+			return "", c.Args, err
+		}
+	}
+	paging := d.paging()
+
+	sb := strings.Builder{}
+	sb.WriteString("SELECT ")
+	if s.Limit > 0 && paging == PagingTop {
+		if s.Offset > 0 {
+			return "", c.Args, ErrOffsetNotSupported
+		}
+		sb.WriteString("TOP " + strconv.Itoa(s.Limit) + " ")
+	}
+	if len(s.Columns) > 0 {
+		sb.WriteString(strings.Join(s.Columns, ", "))
+	} else {
+		sb.WriteString("*")
+	}
+	s.writeFrom(&sb, c)
+
+	paged := (s.Limit > 0 || s.Offset > 0) && paging == PagingOffsetFetch
+	if len(order) == 0 && paged && d.DefaultOrder != "" {
+		order = []string{d.DefaultOrder}
+	}
+	if len(order) > 0 {
+		sb.WriteString(" ORDER BY " + strings.Join(order, ", "))
+	}
+
+	switch {
+	case paging == PagingLimitOffset:
+		if s.Limit > 0 {
+			sb.WriteString(" LIMIT " + strconv.Itoa(s.Limit))
+		}
+		if s.Offset > 0 {
+			sb.WriteString(" OFFSET " + strconv.Itoa(s.Offset))
+		}
+	case paged:
+		sb.WriteString(" OFFSET " + strconv.Itoa(s.Offset) + " ROWS")
+		if s.Limit > 0 {
+			sb.WriteString(" FETCH NEXT " + strconv.Itoa(s.Limit) + " ROWS ONLY")
+		}
+	}
+	return sb.String(), c.Args, nil
+}
+
+// BuildCount builds a statement counting the rows of the SELECT statement without its sort and paging.
+// Grouped rows are counted through a derived table. The arguments are the same as those of Build.
+func (s *Select) BuildCount() (string, []any, error) {
+	c, err := s.clauses()
+	if err != nil {
+		return "", c.Args, err
+	}
+	sb := strings.Builder{}
+	if len(s.GroupBy) > 0 {
+		sb.WriteString("SELECT COUNT(*) FROM (SELECT 1 AS n")
+		s.writeFrom(&sb, c)
+		sb.WriteString(") t")
+		return sb.String(), c.Args, nil
+	}
+	sb.WriteString("SELECT COUNT(*)")
+	s.writeFrom(&sb, c)
+	return sb.String(), c.Args, nil
+}
+
+// clauses builds the clauses from a copy of the Filter, so its Offset is left as it is
+// and the statement builds the same every time
+func (s *Select) clauses() (Clauses, error) {
+	if s.Filter == nil {
+		return Clauses{Args: []any{}}, nil
+	}
+	fb := *s.Filter
+	return fb.BuildClauses()
+}
+
+func (s *Select) writeFrom(sb *strings.Builder, c Clauses) {
+	sb.WriteString(" FROM " + s.From)
+	for _, j := range s.Joins {
+		sb.WriteString(" " + j)
+	}
+	if len(c.Where) > 0 {
+		sb.WriteString(" WHERE " + strings.Join(c.Where, " AND "))
+	}
+	if len(s.GroupBy) > 0 {
+		sb.WriteString(" GROUP BY " + strings.Join(s.GroupBy, ", "))
+	}
+	if len(c.Having) > 0 {
+		sb.WriteString(" HAVING " + strings.Join(c.Having, " AND "))
+	}
+}
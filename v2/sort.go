@@ -0,0 +1,129 @@
+package filterbuilder
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// NullsOrder places NULL values first or last in a sort
+type NullsOrder string
+
+const (
+	NullsDefault NullsOrder = ""      // Database default
+	NullsFirst   NullsOrder = "first" // NULL values sort first
+	NullsLast    NullsOrder = "last"  // NULL values sort last
+)
+
+// Sort is a column of the ORDER BY clause.
+//
+// In JSON and query strings it can also be written as a string, such as "-created_at" for a descending sort,
+// with an optional ":nulls_first" or ":nulls_last" suffix.
+type Sort struct {
+	Column string     `json:"column,omitempty"` // Database table column
+	Desc   bool       `json:"desc,omitempty"`   // Sort in descending order
+	Nulls  NullsOrder `json:"nulls,omitempty"`  // Place NULL values first or last
+}
+
+// ParseSort parses a comma separated sort specification such as "-created_at,name:nulls_last"
+func ParseSort(spec string) ([]Sort, error) {
+	sorts := []Sort{}
+	for _, term := range strings.Split(spec, ",") {
+		if strings.TrimSpace(term) == "" {
+			continue
+		}
+		s, err := parseSortTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		sorts = append(sorts, s)
+	}
+	return sorts, nil
+}
+
+func parseSortTerm(term string) (Sort, error) {
+	var s Sort
+	term = strings.TrimSpace(term)
+	if col, nulls, ok := strings.Cut(term, ":"); ok {
+		switch strings.ToLower(nulls) {
+		case "nulls_first":
+			s.Nulls = NullsFirst
+		case "nulls_last":
+			s.Nulls = NullsLast
+		default:
+			return s, ErrInvalidSort
+		}
+		term = col
+	}
+	switch {
+	case strings.HasPrefix(term, "-"):
+		s.Desc = true
+		term = term[1:]
+	case strings.HasPrefix(term, "+"):
+		term = term[1:]
+	}
+	s.Column = strings.TrimSpace(term)
+	if s.Column == "" {
+		return s, ErrInvalidSort
+	}
+	return s, nil
+}
+
+// SeekKeys gets the keys of a keyset pagination in the sort order
+func SeekKeys(sorts []Sort) []SeekKey {
+	keys := make([]SeekKey, 0, len(sorts))
+	for _, s := range sorts {
+		keys = append(keys, SeekKey{
+			Column: s.Column,
+			Desc:   s.Desc,
+		})
+	}
+	return keys
+}
+
+func (s *Sort) UnmarshalJSON(b []byte) error {
+	var term string
+	if err := json.Unmarshal(b, &term); err == nil {
+		*s, err = parseSortTerm(term)
+		return err
+	}
+	type sort Sort
+	var v sort
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch v.Nulls {
+	case NullsDefault, NullsFirst, NullsLast:
+	default:
+		return ErrInvalidSort
+	}
+	*s = Sort(v)
+	return nil
+}
+
+// buildSort renders the terms of the ORDER BY clause.
+// NULLS FIRST and NULLS LAST are emulated with a CASE term on dialects without them.
+// Without a schema the sort columns must be identifiers, as they are usually read from a query string.
+func buildSort(sorts []Sort, bs *buildState) ([]string, error) {
+	terms := make([]string, 0, len(sorts))
+	for _, s := range sorts {
+		col, err := bs.schema.identifier(s.Column)
+		if err != nil {
+			return nil, err
+		}
+		dir := ""
+		if s.Desc {
+			dir = " DESC"
+		}
+		switch {
+		case s.Nulls == NullsDefault:
+		case bs.nullsOrdering():
+			dir += " NULLS " + strings.ToUpper(string(s.Nulls))
+		case s.Nulls == NullsFirst:
+			terms = append(terms, "CASE WHEN "+col+" IS NULL THEN 0 ELSE 1 END")
+		default:
+			terms = append(terms, "CASE WHEN "+col+" IS NULL THEN 1 ELSE 0 END")
+		}
+		terms = append(terms, col+dir)
+	}
+	return terms, nil
+}
@@ -0,0 +1,131 @@
+package filterbuilder
+
+import "strings"
+
+// TupleIn is IN in SQL over several columns, for looking up rows by composite keys:
+// (tenant_id, order_no) IN ((?,?),(?,?)).
+//
+// Dialects without row values, such as SQL Server, get an OR of AND groups instead.
+// A row with a NULL member is always expanded so it can be tested with IS NULL,
+// and a row with a member unset in the Data is left out.
+type TupleIn struct {
+	Columns []string  `json:"columns,omitempty"` // Database table columns
+	Value   [][]Value `json:"value,omitempty"`   // Rows of values, one value per column
+}
+
+// TupleInRawPair simplifies raw TupleIn pair.
+// Pairs reads the row values raw.
+func TupleInRawPair(columns []string, rows ...[]any) TupleIn {
+	values := make([][]Value, 0, len(rows))
+	for _, r := range rows {
+		row := make([]Value, 0, len(r))
+		for _, v := range r {
+			row = append(row, Value{
+				Src: v,
+				Raw: true,
+			})
+		}
+		values = append(values, row)
+	}
+	return TupleIn{
+		Columns: columns,
+		Value:   values,
+	}
+}
+
+func (f TupleIn) GetPair() any {
+	return f
+}
+
+func (f TupleIn) Build(data any, ph string, inSeq bool, offset int) (string, any, int, error) {
+	return f.build(newBuildState(ph, inSeq), data, offset)
+}
+
+func (f TupleIn) build(bs *buildState, data any, offset int) (string, any, int, error) {
+	cols := make([]string, 0, len(f.Columns))
+	for _, c := range f.Columns {
+		col, err := bs.column(c)
+		if err != nil {
+			return "", nil, offset, err
+		}
+		cols = append(cols, col)
+	}
+	if len(cols) == 0 {
+		return "", nil, offset, ErrTupleSize
+	}
+
+	// Rows with NULL members are expanded, the rest use row values when the dialect has them
+	var rows, nullRows [][]any
+rowLoop:
+	for _, r := range f.Value {
+		if len(r) != len(cols) {
+			return "", nil, offset, ErrTupleSize
+		}
+		vals, err := bs.values(data, r)
+		if err != nil {
+			return "", nil, offset, err
+		}
+		hasNull := false
+		for _, v := range vals {
+			switch v.(type) {
+			case nil:
+				continue rowLoop
+			case Null:
+				hasNull = true
+			case ColumnRef:
+				return "", nil, offset, ErrColumnRefNotSupported
+			}
+		}
+		if err := bs.rowArgs(f.Columns, vals); err != nil {
+			return "", nil, offset, err
+		}
+		if hasNull || !bs.rowValues() {
+			nullRows = append(nullRows, vals)
+			continue
+		}
+		rows = append(rows, vals)
+	}
+
+	if len(rows) == 0 && len(nullRows) == 0 {
+		return "1=0", []any{}, offset, nil
+	}
+
+	var ph string
+	parts := make([]string, 0, 10)
+	args := make([]any, 0, len(cols)*(len(rows)+len(nullRows)))
+
+	size := len(rows)
+	if bs.inStrategy() == InChunk {
+		size = bs.dialect.chunkSize()
+	}
+	tuple := "(" + strings.Join(cols, ", ") + ")"
+	for i := 0; i < len(rows); i += size {
+		chunk := rows[i:min(i+size, len(rows))]
+		tuples := make([]string, 0, len(chunk))
+		for _, r := range chunk {
+			ph, offset = bs.rowParams(cols, offset)
+			tuples = append(tuples, "("+ph+")")
+			args = append(args, r...)
+		}
+		parts = append(parts, tuple+" IN ("+strings.Join(tuples, ",")+")")
+	}
+
+	for _, r := range nullRows {
+		conds := make([]string, 0, len(cols))
+		for i, v := range r {
+			if _, ok := v.(Null); ok {
+				conds = append(conds, cols[i]+" IS NULL")
+				continue
+			}
+			ph, offset = bs.param(cols[i], offset)
+			conds = append(conds, cols[i]+" = "+ph)
+			args = append(args, v)
+		}
+		parts = append(parts, "("+strings.Join(conds, " AND ")+")")
+	}
+
+	if len(parts) == 1 {
+		return parts[0], args, offset, nil
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args, offset, nil
+}
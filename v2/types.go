@@ -0,0 +1,331 @@
+package filterbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	ssd "github.com/shopspring/decimal"
+)
+
+// ColumnType is the declared type of a column. Values compared with a typed column are coerced to the type.
+type ColumnType int
+
+const (
+	TypeAny     ColumnType = iota // Values are bound as they are. JSON numbers become int64 or float64.
+	TypeString                    // string
+	TypeInt                       // int64, from integral numbers and numeric strings
+	TypeDecimal                   // ssd.Decimal, from numbers and numeric strings without precision loss
+	TypeBool                      // bool, from true, false, 1, 0 and their strings
+	TypeTime                      // time.Time, from RFC 3339 strings. Times without a zone are in the Location of the filter.
+	TypeDate                      // time.Time at the start of the day, from 2006-01-02 strings and times
+	TypeUUID                      // Canonical lower case UUID string, from strings and 16 byte values
+	TypeEnum                      // string among the Values of the column
+)
+
+// timeLayouts are the layouts of the strings coerced to TypeTime and TypeDate
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// UnmarshalJSON decodes a Value, keeping numbers as json.Number so no precision is lost
+// before they are coerced to the type of their column.
+func (v *Value) UnmarshalJSON(b []byte) error {
+	type value Value
+	var x value
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&x); err != nil {
+		return err
+	}
+	*v = Value(x)
+	return nil
+}
+
+// coerce coerces a value to the declared type of a column
+func (bs *buildState) coerce(c Column, v any) (any, error) {
+	switch v.(type) {
+	case nil, Null, ColumnRef:
+		return v, nil
+	}
+
+	switch c.Type {
+	case TypeString:
+		return coerceString(v)
+	case TypeInt:
+		return coerceInt(v)
+	case TypeDecimal:
+		return coerceDecimal(v)
+	case TypeBool:
+		return coerceBool(v)
+	case TypeTime:
+		return coerceTime(v, bs.location())
+	case TypeDate:
+		t, err := coerceTime(v, bs.location())
+		if err != nil {
+			return nil, err
+		}
+		return startOfDay(t), nil
+	case TypeUUID:
+		return coerceUUID(v)
+	case TypeEnum:
+		s, err := coerceString(v)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(c.Values, s) {
+			return nil, ErrEnumValue
+		}
+		return s, nil
+	}
+
+	// Untyped JSON numbers are bound as the closest Go number
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, ErrValueType
+		}
+		return f, nil
+	}
+	return v, nil
+}
+
+// location gets the location of the build
+func (bs *buildState) location() *time.Location {
+	if bs.loc != nil {
+		return bs.loc
+	}
+	return time.Local
+}
+
+func coerceString(v any) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case json.Number:
+		return t.String(), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return "", ErrValueType
+}
+
+func coerceInt(v any) (int64, error) {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		d, err := ssd.NewFromString(t.String())
+		if err != nil || !d.IsInteger() {
+			return 0, ErrValueType
+		}
+		return coerceInt(d)
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(t), 10, 64)
+		if err != nil {
+			return 0, ErrValueType
+		}
+		return i, nil
+	case ssd.Decimal:
+		if !t.IsInteger() || t.Cmp(ssd.NewFromInt(math.MaxInt64)) > 0 || t.Cmp(ssd.NewFromInt(math.MinInt64)) < 0 {
+			return 0, ErrValueType
+		}
+		return t.IntPart(), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return 0, ErrValueType
+		}
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, ErrValueType
+		}
+		return int64(f), nil
+	}
+	return 0, ErrValueType
+}
+
+func coerceDecimal(v any) (ssd.Decimal, error) {
+	switch t := v.(type) {
+	case ssd.Decimal:
+		return t, nil
+	case json.Number:
+		d, err := ssd.NewFromString(t.String())
+		if err != nil {
+			return d, ErrValueType
+		}
+		return d, nil
+	case string:
+		d, err := ssd.NewFromString(strings.TrimSpace(t))
+		if err != nil {
+			return d, ErrValueType
+		}
+		return d, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ssd.NewFromInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ssd.NewFromUint64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return ssd.Decimal{}, ErrValueType
+		}
+		return ssd.NewFromFloat(f), nil
+	}
+	return ssd.Decimal{}, ErrValueType
+}
+
+func coerceBool(v any) (bool, error) {
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(t))
+		if err != nil {
+			return false, ErrValueType
+		}
+		return b, nil
+	}
+	i, err := coerceInt(v)
+	if err != nil || (i != 0 && i != 1) {
+		return false, ErrValueType
+	}
+	return i == 1, nil
+}
+
+func coerceTime(v any, loc *time.Location) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		s := strings.TrimSpace(t)
+		for _, layout := range timeLayouts {
+			if tm, err := time.ParseInLocation(layout, s, loc); err == nil {
+				return tm, nil
+			}
+		}
+	}
+	return time.Time{}, ErrValueType
+}
+
+func coerceUUID(v any) (string, error) {
+	if s, ok := v.(string); ok {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if len(s) == 32 {
+			s = s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+		}
+		if len(s) != 36 {
+			return "", ErrValueType
+		}
+		for i := 0; i < len(s); i++ {
+			switch c := s[i]; {
+			case i == 8 || i == 13 || i == 18 || i == 23:
+				if c != '-' {
+					return "", ErrValueType
+				}
+			case c >= '0' && c <= '9', c >= 'a' && c <= 'f':
+			default:
+				return "", ErrValueType
+			}
+		}
+		return s, nil
+	}
+	u, err := UUIDToString(v)
+	if err != nil {
+		return "", err
+	}
+	s, ok := u.(string)
+	if !ok {
+		return "", ErrValueType
+	}
+	return s, nil
+}
+
+// convertValue converts a value to T when it is a number that T can hold exactly, such as an int64 for an int
+// or a JSON number for a float64
+func convertValue[T any](v any) (T, bool) {
+	var zero T
+	if val, ok := v.(T); ok {
+		return val, true
+	}
+	cv, ok := convertTo(v, reflect.TypeOf(zero))
+	if !ok {
+		return zero, false
+	}
+	return cv.Interface().(T), true
+}
+
+// convertTo converts a value to the type to when it is assignable to it,
+// or when it is a number that the type can hold exactly
+func convertTo(v any, to reflect.Type) (reflect.Value, bool) {
+	if to == nil {
+		return reflect.Value{}, false
+	}
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			v = i
+		} else if f, err := n.Float64(); err == nil {
+			v = f
+		} else {
+			return reflect.Value{}, false
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return reflect.Value{}, false
+	}
+	if rv.Type().AssignableTo(to) {
+		return rv, true
+	}
+	if !isNumberKind(rv.Kind()) || !isNumberKind(to.Kind()) || !rv.CanConvert(to) {
+		return reflect.Value{}, false
+	}
+	if rv.CanInt() && rv.Int() < 0 && to.Kind() >= reflect.Uint && to.Kind() <= reflect.Uintptr {
+		return reflect.Value{}, false
+	}
+	cv := rv.Convert(to)
+	if !cv.Convert(rv.Type()).Equal(rv) {
+		return reflect.Value{}, false
+	}
+	return cv, true
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -3,10 +3,16 @@ package filterbuilder
 // Null indicates the column should evaluate for NULL
 type Null bool
 
+// ColumnRef is the resolved value of a column reference.
+// It is rendered as the column itself instead of a parameter.
+type ColumnRef string
+
 // Value struct
 type Value struct {
 	Src any  `json:"src,omitempty"` // Struct field to get value or the value itself
 	Raw bool `json:"raw,omitempty"` // When true, the Src was set to a raw value. When false, the value is retrieved from the struct field in the Data.
+	Rel bool `json:"rel,omitempty"` // When true, the Src is a relative time expression such as "now-7d", resolved upon building.
+	Col bool `json:"col,omitempty"` // When true, the Src is a column to compare with instead of a value.
 }
 
 // Pair struct